@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/anacrolix/missinggo/perf"
 	"github.com/gin-gonic/gin"
@@ -43,13 +44,14 @@ func AddMovie(ctx *gin.Context) {
 
 	tmdbID := ctx.Params.ByName("tmdbId")
 	force := ctx.DefaultQuery("force", falseType) == trueType
+	addedBy := ctx.DefaultQuery("added_by", "")
 
-	movie, err := library.AddMovie(tmdbID, force)
+	movie, err := library.AddMovie(tmdbID, force, addedBy)
 	if err != nil {
 		isErrored := true
 		if err == library.ErrVideoRemoved {
 			if xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30279];;%s", movie.Title)) {
-				movie, err = library.AddMovie(tmdbID, true)
+				movie, err = library.AddMovie(tmdbID, true, addedBy)
 				if err == nil {
 					isErrored = false
 				}
@@ -73,7 +75,9 @@ func AddMovie(ctx *gin.Context) {
 
 	log.Noticef(logMsg, movie.Title, tmdbID)
 	if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("%s;;%s", label, movie.Title))) {
-		xbmc.VideoLibraryScanDirectory(library.MoviesLibraryPath(), true)
+		for _, root := range library.MoviesLibraryPaths() {
+			xbmc.VideoLibraryScanDirectory(root, true)
+		}
 	} else {
 		if ctx != nil {
 			ctx.Abort()
@@ -137,13 +141,14 @@ func AddShow(ctx *gin.Context) {
 
 	tmdbID := ctx.Params.ByName("tmdbId")
 	force := ctx.DefaultQuery("force", falseType) == trueType
+	addedBy := ctx.DefaultQuery("added_by", "")
 
-	show, err := library.AddShow(tmdbID, force)
+	show, err := library.AddShow(tmdbID, force, addedBy)
 	if err != nil {
 		isErrored := true
 		if err == library.ErrVideoRemoved {
 			if xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30279];;%s", show.Name)) {
-				show, err = library.AddShow(tmdbID, true)
+				show, err = library.AddShow(tmdbID, true, addedBy)
 				if err == nil {
 					isErrored = false
 				}
@@ -167,7 +172,9 @@ func AddShow(ctx *gin.Context) {
 
 	log.Noticef(logMsg, show.Name, tmdbID)
 	if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("%s;;%s", label, show.Name))) {
-		xbmc.VideoLibraryScanDirectory(library.ShowsLibraryPath(), true)
+		for _, root := range library.ShowsLibraryPaths() {
+			xbmc.VideoLibraryScanDirectory(root, true)
+		}
 	} else {
 		library.ClearPageCache()
 	}
@@ -227,6 +234,29 @@ func UpdateLibrary(ctx *gin.Context) {
 	}
 }
 
+// WipeLibrary removes all library items, with files=true library folders are emptied as well
+func WipeLibrary(ctx *gin.Context) {
+	removeFiles := ctx.DefaultQuery("files", falseType) == trueType
+
+	message := "LOCALIZE[30669]"
+	if removeFiles {
+		message = "LOCALIZE[30670]"
+	}
+	if !xbmc.DialogConfirmFocused("Elementum", message) {
+		ctx.String(200, "")
+		return
+	}
+
+	if err := library.WipeLibrary(removeFiles); err != nil {
+		ctx.String(200, err.Error())
+		return
+	}
+	if removeFiles {
+		xbmc.VideoLibraryClean()
+	}
+	ctx.String(200, "")
+}
+
 // UpdateTrakt ...
 func UpdateTrakt(ctx *gin.Context) {
 	xbmc.Notify("Elementum", "LOCALIZE[30358]", config.AddonIcon())
@@ -255,3 +285,32 @@ func PlayShow(s *bittorrent.Service) gin.HandlerFunc {
 	}
 	return ShowEpisodeRun("links", s)
 }
+
+// preferQuality moves torrents of the quality, requested by library strm link, like "1080p" or "4K",
+// to the top of the list, so auto-play starts them, other torrents follow in original order
+func preferQuality(torrents []*bittorrent.TorrentFile, quality string) []*bittorrent.TorrentFile {
+	if quality == "" {
+		return torrents
+	}
+
+	preferred := make([]*bittorrent.TorrentFile, 0, len(torrents))
+	others := make([]*bittorrent.TorrentFile, 0, len(torrents))
+	for _, t := range torrents {
+		if t.Resolution > 0 && t.Resolution < len(bittorrent.Resolutions) && strings.EqualFold(bittorrent.Resolutions[t.Resolution], quality) {
+			preferred = append(preferred, t)
+		} else {
+			others = append(others, t)
+		}
+	}
+
+	return append(preferred, others...)
+}
+
+// withPartIndex adds file index of the part, requested by strm link of multi-part movie,
+// to play URL, so each part file starts its own file of the torrent
+func withPartIndex(rURL string, part string) string {
+	if n, err := strconv.Atoi(part); err == nil && n > 0 {
+		return rURL + "&index=" + strconv.Itoa(n-1)
+	}
+	return rURL
+}
@@ -507,6 +507,8 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 		tmdbID := ctx.Params.ByName("tmdbId")
 		external := ctx.Query("external")
 		doresume := ctx.DefaultQuery("doresume", "true")
+		quality := ctx.Query("quality")
+		part := ctx.Query("part")
 
 		runAction := "/play"
 		if action == "download" {
@@ -525,6 +527,7 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"resume", existingTorrent.InfoHash(),
 				"tmdb", tmdbID,
 				"type", "movie")
+			rURL = withPartIndex(rURL, part)
 			if external != "" {
 				xbmc.PlayURL(rURL)
 			} else {
@@ -539,6 +542,7 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"uri", torrent.URI,
 				"tmdb", tmdbID,
 				"type", "movie")
+			rURL = withPartIndex(rURL, part)
 			if external != "" {
 				xbmc.PlayURL(rURL)
 			} else {
@@ -561,6 +565,8 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			return
 		}
 
+		torrents = preferQuality(torrents, quality)
+
 		choices := make([]string, 0, len(torrents))
 		for _, torrent := range torrents {
 			resolution := ""
@@ -617,6 +623,7 @@ func MovieLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 				"doresume", doresume,
 				"tmdb", tmdbID,
 				"type", "movie")
+			rURL = withPartIndex(rURL, part)
 			if external != "" {
 				xbmc.PlayURL(rURL)
 			} else {
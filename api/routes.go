@@ -300,6 +300,7 @@ func Routes(s *bittorrent.Service) *gin.Engine {
 		library.GET("/show/play/:showId/:season/:episode", PlayShow(s))
 
 		library.GET("/update", UpdateLibrary)
+		library.GET("/wipe", WipeLibrary)
 
 		// DEPRECATED
 		library.GET("/play/movie/:tmdbId", PlayMovie(s))
@@ -785,6 +785,7 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 		external := ctx.Query("external")
 		doresume := ctx.DefaultQuery("doresume", "true")
 		silent := ctx.DefaultQuery("silent", "")
+		quality := ctx.Query("quality")
 
 		runAction := "/play"
 		if action == "download" {
@@ -860,6 +861,8 @@ func ShowEpisodeLinks(action string, s *bittorrent.Service) gin.HandlerFunc {
 			return
 		}
 
+		torrents = preferQuality(torrents, quality)
+
 		choices := make([]string, 0, len(torrents))
 		for _, torrent := range torrents {
 			resolution := ""
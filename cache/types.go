@@ -14,6 +14,8 @@ const (
 	LibraryKey = "library."
 	FanartKey  = "fanart."
 
+	TMDBCollectionKey              = TMDBKey + "collection.%d.%s"
+	TMDBCollectionExpire           = GeneralExpire
 	TMDBEpisodeKey                 = TMDBKey + "episode.%d.%d.%d.%s"
 	TMDBEpisodeExpire              = GeneralExpire
 	TMDBFindKey                    = TMDBKey + "find.%s.%s"
@@ -57,6 +59,8 @@ const (
 	TraktActivitiesExpire                  = 30 * 24 * time.Hour
 	TraktPausedLastUpdatesKey              = TraktKey + "PausedLastUpdates.%d"
 	TraktPausedLastUpdatesExpire           = 30 * 24 * time.Hour
+	TraktListSchedulesKey                  = TraktKey + "ListSchedules"
+	TraktListSchedulesExpire               = 365 * 24 * time.Hour
 	TraktMovieKey                          = TraktKey + "movie.%s"
 	TraktMovieExpire                       = GeneralExpire
 	TraktMovieByTMDBKey                    = TraktKey + "movie.tmdb.%s"
@@ -138,14 +142,27 @@ const (
 	FanartShowByIDKey     = FanartKey + "show.%d"
 	FanartShowByIDExpire  = GeneralExpire
 
-	LibraryWatchedPlaycountKey    = LibraryKey + "WatchedLastPlaycount.%s"
-	LibraryWatchedPlaycountExpire = 30 * 24 * time.Hour
-	LibraryShowsLastUpdatesKey    = LibraryKey + "showsLastUpdates"
-	LibraryShowsLastUpdatesExpire = 7 * 24 * time.Hour
-	LibraryResolveFileKey         = LibraryKey + "Resolve_File_%s"
-	LibraryResolveFileExpire      = 60 * 24 * time.Hour
-	LibrarySyncPlaycountKey       = LibraryKey + "SyncLastPlaycount.%s"
-	LibrarySyncPlaycountExpire    = 30 * 24 * time.Hour
+	LibraryWatchedPlaycountKey     = LibraryKey + "WatchedLastPlaycount.%s"
+	LibraryWatchedPlaycountExpire  = 30 * 24 * time.Hour
+	LibraryShowsLastUpdatesKey     = LibraryKey + "showsLastUpdates"
+	LibraryShowsLastUpdatesExpire  = 7 * 24 * time.Hour
+	LibraryMoviesLastUpdatesKey    = LibraryKey + "moviesLastUpdates"
+	LibraryMoviesLastUpdatesExpire = 7 * 24 * time.Hour
+	LibrarySyncCursorPrefix        = LibraryKey + "SyncCursor."
+	LibrarySyncCursorKey           = LibrarySyncCursorPrefix + "%d.%s.%s"
+	LibrarySyncCursorExpire        = 24 * time.Hour
+	LibraryResolveFileKey          = LibraryKey + "Resolve_File_%s"
+	LibraryResolveFileExpire       = 60 * 24 * time.Hour
+	LibraryResolveIDKey            = LibraryKey + "Resolve_ID_%d_%s_%s"
+	LibraryResolveIDExpire         = 60 * 24 * time.Hour
+	LibrarySyncPlaycountKey        = LibraryKey + "SyncLastPlaycount.%s"
+	LibrarySyncPlaycountExpire     = 30 * 24 * time.Hour
+	LibrarySchemaVersionKey        = LibraryKey + "SchemaVersion"
+	LibrarySchemaVersionExpire     = 10 * 365 * 24 * time.Hour
+	LibraryMigratedVersionKey      = LibraryKey + "MigratedVersion"
+	LibraryMigratedVersionExpire   = 10 * 365 * 24 * time.Hour
+	LibraryTrashKey                = LibraryKey + "Trash"
+	LibraryTrashExpire             = 10 * 365 * 24 * time.Hour
 
 	ScraperLastExecutionKey    = ScraperKey + "last.execution"
 	ScraperLastExecutionExpire = 60 * 60 * 24 * 30
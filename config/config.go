@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -30,6 +31,10 @@ const (
 	maxMemorySize                = 400 * 1024 * 1024
 	defaultAutoMemorySize        = 40 * 1024 * 1024
 	defaultTraktSyncFrequencyMin = 5
+	defaultLibraryRemoveMaxDirs  = 50
+	defaultLibraryTrashDays      = 30
+	defaultLibraryWriteAttempts  = 3
+	defaultMovieStrmTemplate     = "{title} ({year})"
 	defaultEndBufferSize         = 1 * 1024 * 1024
 	defaultDiskCacheSize         = 12 * 1024 * 1024
 
@@ -45,89 +50,136 @@ const (
 
 // Configuration ...
 type Configuration struct {
-	DownloadPath                string
-	TorrentsPath                string
-	LibraryPath                 string
-	Info                        *xbmc.AddonInfo
-	Platform                    *xbmc.Platform
-	Language                    string
-	Region                      string
-	TemporaryPath               string
-	ProfilePath                 string
-	HomePath                    string
-	XbmcPath                    string
-	SpoofUserAgent              int
-	DownloadFileStrategy        int
-	KeepDownloading             int
-	KeepFilesPlaying            int
-	KeepFilesFinished           int
-	UseTorrentHistory           bool
-	TorrentHistorySize          int
-	UseFanartTv                 bool
-	DisableBgProgress           bool
-	DisableBgProgressPlayback   bool
-	ForceUseTrakt               bool
-	UseCacheSelection           bool
-	UseCacheSearch              bool
-	UseCacheTorrents            bool
-	CacheSearchDuration         int
-	ShowFilesWatched            bool
-	ResultsPerPage              int
-	GreetingEnabled             bool
-	EnableOverlayStatus         bool
-	SilentStreamStart           bool
-	AutoYesEnabled              bool
-	AutoYesTimeout              int
-	ChooseStreamAutoMovie       bool
-	ChooseStreamAutoShow        bool
-	ChooseStreamAutoSearch      bool
-	ForceLinkType               bool
-	UseOriginalTitle            bool
-	UseAnimeEnTitle             bool
-	UseLowestReleaseDate        bool
-	AddSpecials                 bool
-	AddEpisodeNumbers           bool
-	ShowUnairedSeasons          bool
-	ShowUnairedEpisodes         bool
-	ShowEpisodesOnReleaseDay    bool
-	ShowUnwatchedEpisodesNumber bool
-	ShowSeasonsAll              bool
-	ShowSeasonsOrder            int
-	ShowSeasonsSpecials         bool
-	SmartEpisodeStart           bool
-	SmartEpisodeMatch           bool
-	SmartEpisodeChoose          bool
-	LibraryEnabled              bool
-	LibrarySyncEnabled          bool
-	LibrarySyncPlaybackEnabled  bool
-	LibraryUpdate               int
-	StrmLanguage                string
-	LibraryNFOMovies            bool
-	LibraryNFOShows             bool
-	PlaybackPercent             int
-	DownloadStorage             int
-	SkipBurstSearch             bool
-	AutoMemorySize              bool
-	AutoKodiBufferSize          bool
-	AutoAdjustMemorySize        bool
-	AutoMemorySizeStrategy      int
-	MemorySize                  int
-	AutoAdjustBufferSize        bool
-	MinCandidateSize            int64
-	MinCandidateShowSize        int64
-	BufferTimeout               int
-	BufferSize                  int
-	EndBufferSize               int
-	KodiBufferSize              int
-	UploadRateLimit             int
-	DownloadRateLimit           int
-	AutoloadTorrents            bool
-	AutoloadTorrentsPaused      bool
-	LimitAfterBuffering         bool
-	ConnectionsLimit            int
-	ConnTrackerLimit            int
-	ConnTrackerLimitAuto        bool
-	SessionSave                 int
+	DownloadPath                  string
+	TorrentsPath                  string
+	LibraryPath                   string
+	LibraryExtraPaths             []string
+	LibraryRootPolicy             int
+	LibraryNamingProfile          int
+	Info                          *xbmc.AddonInfo
+	Platform                      *xbmc.Platform
+	Language                      string
+	Region                        string
+	TemporaryPath                 string
+	ProfilePath                   string
+	HomePath                      string
+	XbmcPath                      string
+	SpoofUserAgent                int
+	DownloadFileStrategy          int
+	KeepDownloading               int
+	KeepFilesPlaying              int
+	KeepFilesFinished             int
+	UseTorrentHistory             bool
+	TorrentHistorySize            int
+	UseFanartTv                   bool
+	DisableBgProgress             bool
+	DisableBgProgressPlayback     bool
+	ForceUseTrakt                 bool
+	UseCacheSelection             bool
+	UseCacheSearch                bool
+	UseCacheTorrents              bool
+	CacheSearchDuration           int
+	ShowFilesWatched              bool
+	ResultsPerPage                int
+	GreetingEnabled               bool
+	EnableOverlayStatus           bool
+	SilentStreamStart             bool
+	AutoYesEnabled                bool
+	AutoYesTimeout                int
+	ChooseStreamAutoMovie         bool
+	ChooseStreamAutoShow          bool
+	ChooseStreamAutoSearch        bool
+	ForceLinkType                 bool
+	UseOriginalTitle              bool
+	UseAnimeEnTitle               bool
+	UseLowestReleaseDate          bool
+	AddSpecials                   bool
+	ShowSeasonFolders             bool
+	AddEpisodeNumbers             bool
+	ShowUnairedSeasons            bool
+	ShowUnairedEpisodes           bool
+	ShowEpisodesOnReleaseDay      bool
+	ShowUnwatchedEpisodesNumber   bool
+	ShowSeasonsAll                bool
+	ShowSeasonsOrder              int
+	ShowSeasonsSpecials           bool
+	SmartEpisodeStart             bool
+	SmartEpisodeMatch             bool
+	SmartEpisodeChoose            bool
+	LibraryEnabled                bool
+	LibrarySyncEnabled            bool
+	LibrarySyncPlaybackEnabled    bool
+	LibraryUpdate                 int
+	StrmLanguage                  string
+	LibraryNFOMovies              bool
+	LibraryNFOShows               bool
+	LibraryNFOEpisodes            bool
+	LibraryNFOSeasons             bool
+	LibraryPruneStaleEpisodes     bool
+	MovieStrmTemplate             string
+	LibraryFolderUseID            bool
+	MovieLibraryFlat              bool
+	MovieFolderGrouping           int
+	MovieGroupBySet               bool
+	MovieStrmParts                int
+	AnimeAbsoluteNumbering        bool
+	AnimeAbsoluteShows            []int
+	StrmURLMode                   int
+	LibraryTraktRemoveDeleted     bool
+	LibraryNFOFull                bool
+	LibraryNFOCastCount           int
+	ReleaseRegion                 string
+	ConfirmTimeoutSeconds         int
+	ConfirmTimeoutDefault         bool
+	LibraryManifest               bool
+	LibraryRemoveMaxDirs          int
+	LibraryWriteAttempts          int
+	LibraryTrashEnabled           bool
+	LibraryWebhookURL             string
+	LibraryCompactDatabase        bool
+	LibraryTrashDays              int
+	DeletedItemRetentionDays      int
+	PreserveResumeOnRewrite       bool
+	ScrubIntervalHours            int
+	ScrubAutoRepair               bool
+	IncludeEpisodeTitleInFilename bool
+	LibraryDownloadArtwork        bool
+	MovieTitleAliases             map[int]string
+	ShowTitleAliases              map[int]string
+	NotifyProfiles                []string
+	NotifyNewEpisodes             bool
+	MovieForceQuality             string
+	ShowForceQuality              string
+	KeepLastNSeasons              int
+	ShowSeasonRanges              map[int][2]int
+	LibraryMinRating              float32
+	LibraryMinVotes               int
+	ExcludeGenres                 []int
+	ScanDebounceSeconds           int
+	PlaybackPercent               int
+	DownloadStorage               int
+	SkipBurstSearch               bool
+	AutoMemorySize                bool
+	AutoKodiBufferSize            bool
+	AutoAdjustMemorySize          bool
+	AutoMemorySizeStrategy        int
+	MemorySize                    int
+	AutoAdjustBufferSize          bool
+	MinCandidateSize              int64
+	MinCandidateShowSize          int64
+	BufferTimeout                 int
+	BufferSize                    int
+	EndBufferSize                 int
+	KodiBufferSize                int
+	UploadRateLimit               int
+	DownloadRateLimit             int
+	AutoloadTorrents              bool
+	AutoloadTorrentsPaused        bool
+	LimitAfterBuffering           bool
+	ConnectionsLimit              int
+	ConnTrackerLimit              int
+	ConnTrackerLimitAuto          bool
+	SessionSave                   int
 
 	SeedForever        bool
 	ShareRatioLimit    int
@@ -179,6 +231,9 @@ type Configuration struct {
 	TraktSyncCollections           bool
 	TraktSyncWatchlist             bool
 	TraktSyncUserlists             bool
+	TraktSyncLikedlists            bool
+	TraktSyncLikedlistsIDs         []string
+	TraktListIntervals             map[string]int
 	TraktSyncPlaybackProgress      bool
 	TraktSyncHidden                bool
 	TraktSyncWatched               bool
@@ -500,117 +555,157 @@ func Reload() *Configuration {
 	}
 
 	newConfig := Configuration{
-		DownloadPath:                downloadPath,
-		LibraryPath:                 libraryPath,
-		TorrentsPath:                torrentsPath,
-		Info:                        info,
-		Platform:                    platform,
-		Language:                    xbmc.GetLanguageISO639_1(),
-		Region:                      xbmc.GetRegion(),
-		TemporaryPath:               info.TempPath,
-		ProfilePath:                 info.Profile,
-		HomePath:                    info.Home,
-		XbmcPath:                    info.Xbmc,
-		DownloadStorage:             settings.ToInt("download_storage"),
-		SkipBurstSearch:             settings.ToBool("skip_burst_search"),
-		AutoMemorySize:              settings.ToBool("auto_memory_size"),
-		AutoAdjustMemorySize:        settings.ToBool("auto_adjust_memory_size"),
-		AutoMemorySizeStrategy:      settings.ToInt("auto_memory_size_strategy"),
-		MemorySize:                  settings.ToInt("memory_size") * 1024 * 1024,
-		AutoKodiBufferSize:          settings.ToBool("auto_kodi_buffer_size"),
-		AutoAdjustBufferSize:        settings.ToBool("auto_adjust_buffer_size"),
-		MinCandidateSize:            int64(settings.ToInt("min_candidate_size") * 1024 * 1024),
-		MinCandidateShowSize:        int64(settings.ToInt("min_candidate_show_size") * 1024 * 1024),
-		BufferTimeout:               settings.ToInt("buffer_timeout"),
-		BufferSize:                  settings.ToInt("buffer_size") * 1024 * 1024,
-		EndBufferSize:               settings.ToInt("end_buffer_size") * 1024 * 1024,
-		UploadRateLimit:             settings.ToInt("max_upload_rate") * 1024,
-		DownloadRateLimit:           settings.ToInt("max_download_rate") * 1024,
-		AutoloadTorrents:            settings.ToBool("autoload_torrents"),
-		AutoloadTorrentsPaused:      settings.ToBool("autoload_torrents_paused"),
-		SpoofUserAgent:              settings.ToInt("spoof_user_agent"),
-		LimitAfterBuffering:         settings.ToBool("limit_after_buffering"),
-		DownloadFileStrategy:        settings.ToInt("download_file_strategy"),
-		KeepDownloading:             settings.ToInt("keep_downloading"),
-		KeepFilesPlaying:            settings.ToInt("keep_files_playing"),
-		KeepFilesFinished:           settings.ToInt("keep_files_finished"),
-		UseTorrentHistory:           settings.ToBool("use_torrent_history"),
-		TorrentHistorySize:          settings.ToInt("torrent_history_size"),
-		UseFanartTv:                 settings.ToBool("use_fanart_tv"),
-		DisableBgProgress:           settings.ToBool("disable_bg_progress"),
-		DisableBgProgressPlayback:   settings.ToBool("disable_bg_progress_playback"),
-		ForceUseTrakt:               settings.ToBool("force_use_trakt"),
-		UseCacheSelection:           settings.ToBool("use_cache_selection"),
-		UseCacheSearch:              settings.ToBool("use_cache_search"),
-		UseCacheTorrents:            settings.ToBool("use_cache_torrents"),
-		CacheSearchDuration:         settings.ToInt("cache_search_duration"),
-		ResultsPerPage:              settings.ToInt("results_per_page"),
-		ShowFilesWatched:            settings.ToBool("show_files_watched"),
-		GreetingEnabled:             settings.ToBool("greeting_enabled"),
-		EnableOverlayStatus:         settings.ToBool("enable_overlay_status"),
-		SilentStreamStart:           settings.ToBool("silent_stream_start"),
-		AutoYesEnabled:              settings.ToBool("autoyes_enabled"),
-		AutoYesTimeout:              settings.ToInt("autoyes_timeout"),
-		ChooseStreamAutoMovie:       settings.ToBool("choose_stream_auto_movie"),
-		ChooseStreamAutoShow:        settings.ToBool("choose_stream_auto_show"),
-		ChooseStreamAutoSearch:      settings.ToBool("choose_stream_auto_search"),
-		ForceLinkType:               settings.ToBool("force_link_type"),
-		UseOriginalTitle:            settings.ToBool("use_original_title"),
-		UseAnimeEnTitle:             settings.ToBool("use_anime_en_title"),
-		UseLowestReleaseDate:        settings.ToBool("use_lowest_release_date"),
-		AddSpecials:                 settings.ToBool("add_specials"),
-		AddEpisodeNumbers:           settings.ToBool("add_episode_numbers"),
-		ShowUnairedSeasons:          settings.ToBool("unaired_seasons"),
-		ShowUnairedEpisodes:         settings.ToBool("unaired_episodes"),
-		ShowEpisodesOnReleaseDay:    settings.ToBool("show_episodes_on_release_day"),
-		ShowUnwatchedEpisodesNumber: settings.ToBool("show_unwatched_episodes_number"),
-		ShowSeasonsAll:              settings.ToBool("seasons_all"),
-		ShowSeasonsOrder:            settings.ToInt("seasons_order"),
-		ShowSeasonsSpecials:         settings.ToBool("seasons_specials"),
-		PlaybackPercent:             settings.ToInt("playback_percent"),
-		SmartEpisodeStart:           settings.ToBool("smart_episode_start"),
-		SmartEpisodeMatch:           settings.ToBool("smart_episode_match"),
-		SmartEpisodeChoose:          settings.ToBool("smart_episode_choose"),
-		LibraryEnabled:              settings.ToBool("library_enabled"),
-		LibrarySyncEnabled:          settings.ToBool("library_sync_enabled"),
-		LibrarySyncPlaybackEnabled:  settings.ToBool("library_sync_playback_enabled"),
-		LibraryUpdate:               settings.ToInt("library_update"),
-		StrmLanguage:                settings.ToString("strm_language"),
-		LibraryNFOMovies:            settings.ToBool("library_nfo_movies"),
-		LibraryNFOShows:             settings.ToBool("library_nfo_shows"),
-		SeedForever:                 settings.ToBool("seed_forever"),
-		ShareRatioLimit:             settings.ToInt("share_ratio_limit"),
-		SeedTimeRatioLimit:          settings.ToInt("seed_time_ratio_limit"),
-		SeedTimeLimit:               settings.ToInt("seed_time_limit") * 3600,
-		DisableUpload:               settings.ToBool("disable_upload"),
-		DisableLSD:                  settings.ToBool("disable_lsd"),
-		DisableDHT:                  settings.ToBool("disable_dht"),
-		DisableTCP:                  settings.ToBool("disable_tcp"),
-		DisableUTP:                  settings.ToBool("disable_utp"),
-		DisableUPNP:                 settings.ToBool("disable_upnp"),
-		EncryptionPolicy:            settings.ToInt("encryption_policy"),
-		ListenPortMin:               settings.ToInt("listen_port_min"),
-		ListenPortMax:               settings.ToInt("listen_port_max"),
-		ListenInterfaces:            settings.ToString("listen_interfaces"),
-		ListenAutoDetectIP:          settings.ToBool("listen_autodetect_ip"),
-		ListenAutoDetectPort:        settings.ToBool("listen_autodetect_port"),
-		OutgoingInterfaces:          settings.ToString("outgoing_interfaces"),
-		TunedStorage:                settings.ToBool("tuned_storage"),
-		DiskCacheSize:               settings.ToInt("disk_cache_size") * 1024 * 1024,
-		UseLibtorrentConfig:         settings.ToBool("use_libtorrent_config"),
-		UseLibtorrentLogging:        settings.ToBool("use_libtorrent_logging"),
-		UseLibtorrentDeadlines:      settings.ToBool("use_libtorrent_deadline"),
-		UseLibtorrentPauseResume:    settings.ToBool("use_libtorrent_pauseresume"),
-		LibtorrentProfile:           settings.ToInt("libtorrent_profile"),
-		MagnetResolveTimeout:        settings.ToInt("magnet_resolve_timeout"),
-		AddExtraTrackers:            settings.ToInt("add_extra_trackers"),
-		RemoveOriginalTrackers:      settings.ToBool("remove_original_trackers"),
-		ModifyTrackersStrategy:      settings.ToInt("modify_trackers_strategy"),
-		ConnectionsLimit:            settings.ToInt("connections_limit"),
-		ConnTrackerLimit:            settings.ToInt("conntracker_limit"),
-		ConnTrackerLimitAuto:        settings.ToBool("conntracker_limit_auto"),
-		SessionSave:                 settings.ToInt("session_save"),
-		Scrobble:                    settings.ToBool("trakt_scrobble"),
+		DownloadPath:                  downloadPath,
+		LibraryPath:                   libraryPath,
+		TorrentsPath:                  torrentsPath,
+		Info:                          info,
+		Platform:                      platform,
+		Language:                      xbmc.GetLanguageISO639_1(),
+		Region:                        xbmc.GetRegion(),
+		TemporaryPath:                 info.TempPath,
+		ProfilePath:                   info.Profile,
+		HomePath:                      info.Home,
+		XbmcPath:                      info.Xbmc,
+		DownloadStorage:               settings.ToInt("download_storage"),
+		SkipBurstSearch:               settings.ToBool("skip_burst_search"),
+		AutoMemorySize:                settings.ToBool("auto_memory_size"),
+		AutoAdjustMemorySize:          settings.ToBool("auto_adjust_memory_size"),
+		AutoMemorySizeStrategy:        settings.ToInt("auto_memory_size_strategy"),
+		MemorySize:                    settings.ToInt("memory_size") * 1024 * 1024,
+		AutoKodiBufferSize:            settings.ToBool("auto_kodi_buffer_size"),
+		AutoAdjustBufferSize:          settings.ToBool("auto_adjust_buffer_size"),
+		MinCandidateSize:              int64(settings.ToInt("min_candidate_size") * 1024 * 1024),
+		MinCandidateShowSize:          int64(settings.ToInt("min_candidate_show_size") * 1024 * 1024),
+		BufferTimeout:                 settings.ToInt("buffer_timeout"),
+		BufferSize:                    settings.ToInt("buffer_size") * 1024 * 1024,
+		EndBufferSize:                 settings.ToInt("end_buffer_size") * 1024 * 1024,
+		UploadRateLimit:               settings.ToInt("max_upload_rate") * 1024,
+		DownloadRateLimit:             settings.ToInt("max_download_rate") * 1024,
+		AutoloadTorrents:              settings.ToBool("autoload_torrents"),
+		AutoloadTorrentsPaused:        settings.ToBool("autoload_torrents_paused"),
+		SpoofUserAgent:                settings.ToInt("spoof_user_agent"),
+		LimitAfterBuffering:           settings.ToBool("limit_after_buffering"),
+		DownloadFileStrategy:          settings.ToInt("download_file_strategy"),
+		KeepDownloading:               settings.ToInt("keep_downloading"),
+		KeepFilesPlaying:              settings.ToInt("keep_files_playing"),
+		KeepFilesFinished:             settings.ToInt("keep_files_finished"),
+		UseTorrentHistory:             settings.ToBool("use_torrent_history"),
+		TorrentHistorySize:            settings.ToInt("torrent_history_size"),
+		UseFanartTv:                   settings.ToBool("use_fanart_tv"),
+		DisableBgProgress:             settings.ToBool("disable_bg_progress"),
+		DisableBgProgressPlayback:     settings.ToBool("disable_bg_progress_playback"),
+		ForceUseTrakt:                 settings.ToBool("force_use_trakt"),
+		UseCacheSelection:             settings.ToBool("use_cache_selection"),
+		UseCacheSearch:                settings.ToBool("use_cache_search"),
+		UseCacheTorrents:              settings.ToBool("use_cache_torrents"),
+		CacheSearchDuration:           settings.ToInt("cache_search_duration"),
+		ResultsPerPage:                settings.ToInt("results_per_page"),
+		ShowFilesWatched:              settings.ToBool("show_files_watched"),
+		GreetingEnabled:               settings.ToBool("greeting_enabled"),
+		EnableOverlayStatus:           settings.ToBool("enable_overlay_status"),
+		SilentStreamStart:             settings.ToBool("silent_stream_start"),
+		AutoYesEnabled:                settings.ToBool("autoyes_enabled"),
+		AutoYesTimeout:                settings.ToInt("autoyes_timeout"),
+		ChooseStreamAutoMovie:         settings.ToBool("choose_stream_auto_movie"),
+		ChooseStreamAutoShow:          settings.ToBool("choose_stream_auto_show"),
+		ChooseStreamAutoSearch:        settings.ToBool("choose_stream_auto_search"),
+		ForceLinkType:                 settings.ToBool("force_link_type"),
+		UseOriginalTitle:              settings.ToBool("use_original_title"),
+		UseAnimeEnTitle:               settings.ToBool("use_anime_en_title"),
+		UseLowestReleaseDate:          settings.ToBool("use_lowest_release_date"),
+		AddSpecials:                   settings.ToBool("add_specials"),
+		ShowSeasonFolders:             settings.ToBool("library_show_season_folders"),
+		AddEpisodeNumbers:             settings.ToBool("add_episode_numbers"),
+		ShowUnairedSeasons:            settings.ToBool("unaired_seasons"),
+		ShowUnairedEpisodes:           settings.ToBool("unaired_episodes"),
+		ShowEpisodesOnReleaseDay:      settings.ToBool("show_episodes_on_release_day"),
+		ShowUnwatchedEpisodesNumber:   settings.ToBool("show_unwatched_episodes_number"),
+		ShowSeasonsAll:                settings.ToBool("seasons_all"),
+		ShowSeasonsOrder:              settings.ToInt("seasons_order"),
+		ShowSeasonsSpecials:           settings.ToBool("seasons_specials"),
+		PlaybackPercent:               settings.ToInt("playback_percent"),
+		SmartEpisodeStart:             settings.ToBool("smart_episode_start"),
+		SmartEpisodeMatch:             settings.ToBool("smart_episode_match"),
+		SmartEpisodeChoose:            settings.ToBool("smart_episode_choose"),
+		LibraryEnabled:                settings.ToBool("library_enabled"),
+		LibrarySyncEnabled:            settings.ToBool("library_sync_enabled"),
+		LibrarySyncPlaybackEnabled:    settings.ToBool("library_sync_playback_enabled"),
+		LibraryUpdate:                 settings.ToInt("library_update"),
+		StrmLanguage:                  settings.ToString("strm_language"),
+		LibraryNFOMovies:              settings.ToBool("library_nfo_movies"),
+		LibraryNFOShows:               settings.ToBool("library_nfo_shows"),
+		LibraryNFOEpisodes:            settings.ToBool("library_nfo_episodes"),
+		LibraryNFOSeasons:             settings.ToBool("library_nfo_seasons"),
+		LibraryPruneStaleEpisodes:     settings.ToBool("library_prune_stale_episodes"),
+		MovieStrmTemplate:             settings.ToString("library_movie_strm_template"),
+		LibraryFolderUseID:            settings.ToBool("library_folder_use_id"),
+		MovieLibraryFlat:              settings.ToBool("library_movie_flat"),
+		MovieFolderGrouping:           settings.ToInt("library_movie_grouping"),
+		MovieGroupBySet:               settings.ToBool("library_movie_group_by_set"),
+		MovieStrmParts:                settings.ToInt("library_movie_strm_parts"),
+		AnimeAbsoluteNumbering:        settings.ToBool("library_anime_absolute"),
+		StrmURLMode:                   settings.ToInt("library_strm_url_mode"),
+		LibraryTraktRemoveDeleted:     settings.ToBool("library_trakt_remove_deleted"),
+		LibraryNFOFull:                settings.ToBool("library_nfo_full"),
+		LibraryNFOCastCount:           settings.ToInt("library_nfo_cast_count"),
+		ReleaseRegion:                 strings.ToUpper(settings.ToString("library_release_region")),
+		ConfirmTimeoutSeconds:         settings.ToInt("library_confirm_timeout"),
+		ConfirmTimeoutDefault:         settings.ToBool("library_confirm_timeout_default"),
+		LibraryManifest:               settings.ToBool("library_manifest"),
+		LibraryRemoveMaxDirs:          settings.ToInt("library_remove_max_dirs"),
+		LibraryRootPolicy:             settings.ToInt("library_root_policy"),
+		LibraryNamingProfile:          settings.ToInt("library_naming_profile"),
+		LibraryWriteAttempts:          settings.ToInt("library_write_attempts"),
+		LibraryTrashEnabled:           settings.ToBool("library_trash_enabled"),
+		LibraryWebhookURL:             strings.TrimSpace(settings.ToString("library_webhook_url")),
+		LibraryCompactDatabase:        settings.ToBool("library_compact_database"),
+		LibraryTrashDays:              settings.ToInt("library_trash_days"),
+		DeletedItemRetentionDays:      settings.ToInt("library_deleted_retention_days"),
+		PreserveResumeOnRewrite:       settings.ToBool("library_preserve_resume"),
+		ScrubIntervalHours:            settings.ToInt("library_scrub_interval"),
+		ScrubAutoRepair:               settings.ToBool("library_scrub_auto_repair"),
+		NotifyNewEpisodes:             settings.ToBool("library_notify_new_episodes"),
+		IncludeEpisodeTitleInFilename: settings.ToBool("library_episode_title_filename"),
+		LibraryDownloadArtwork:        settings.ToBool("library_download_artwork"),
+		MovieForceQuality:             settings.ToString("library_movie_force_quality"),
+		ShowForceQuality:              settings.ToString("library_show_force_quality"),
+		KeepLastNSeasons:              settings.ToInt("library_keep_last_seasons"),
+		LibraryMinRating:              settings.ToFloat32("library_min_rating"),
+		LibraryMinVotes:               settings.ToInt("library_min_votes"),
+		ScanDebounceSeconds:           settings.ToInt("library_scan_debounce"),
+		SeedForever:                   settings.ToBool("seed_forever"),
+		ShareRatioLimit:               settings.ToInt("share_ratio_limit"),
+		SeedTimeRatioLimit:            settings.ToInt("seed_time_ratio_limit"),
+		SeedTimeLimit:                 settings.ToInt("seed_time_limit") * 3600,
+		DisableUpload:                 settings.ToBool("disable_upload"),
+		DisableLSD:                    settings.ToBool("disable_lsd"),
+		DisableDHT:                    settings.ToBool("disable_dht"),
+		DisableTCP:                    settings.ToBool("disable_tcp"),
+		DisableUTP:                    settings.ToBool("disable_utp"),
+		DisableUPNP:                   settings.ToBool("disable_upnp"),
+		EncryptionPolicy:              settings.ToInt("encryption_policy"),
+		ListenPortMin:                 settings.ToInt("listen_port_min"),
+		ListenPortMax:                 settings.ToInt("listen_port_max"),
+		ListenInterfaces:              settings.ToString("listen_interfaces"),
+		ListenAutoDetectIP:            settings.ToBool("listen_autodetect_ip"),
+		ListenAutoDetectPort:          settings.ToBool("listen_autodetect_port"),
+		OutgoingInterfaces:            settings.ToString("outgoing_interfaces"),
+		TunedStorage:                  settings.ToBool("tuned_storage"),
+		DiskCacheSize:                 settings.ToInt("disk_cache_size") * 1024 * 1024,
+		UseLibtorrentConfig:           settings.ToBool("use_libtorrent_config"),
+		UseLibtorrentLogging:          settings.ToBool("use_libtorrent_logging"),
+		UseLibtorrentDeadlines:        settings.ToBool("use_libtorrent_deadline"),
+		UseLibtorrentPauseResume:      settings.ToBool("use_libtorrent_pauseresume"),
+		LibtorrentProfile:             settings.ToInt("libtorrent_profile"),
+		MagnetResolveTimeout:          settings.ToInt("magnet_resolve_timeout"),
+		AddExtraTrackers:              settings.ToInt("add_extra_trackers"),
+		RemoveOriginalTrackers:        settings.ToBool("remove_original_trackers"),
+		ModifyTrackersStrategy:        settings.ToInt("modify_trackers_strategy"),
+		ConnectionsLimit:              settings.ToInt("connections_limit"),
+		ConnTrackerLimit:              settings.ToInt("conntracker_limit"),
+		ConnTrackerLimitAuto:          settings.ToBool("conntracker_limit_auto"),
+		SessionSave:                   settings.ToInt("session_save"),
+		Scrobble:                      settings.ToBool("trakt_scrobble"),
 
 		AutoScrapeEnabled:        settings.ToBool("autoscrape_is_enabled"),
 		AutoScrapeLibraryEnabled: settings.ToBool("autoscrape_library_enabled"),
@@ -630,6 +725,7 @@ func Reload() *Configuration {
 		TraktSyncCollections:           settings.ToBool("trakt_sync_collections"),
 		TraktSyncWatchlist:             settings.ToBool("trakt_sync_watchlist"),
 		TraktSyncUserlists:             settings.ToBool("trakt_sync_userlists"),
+		TraktSyncLikedlists:            settings.ToBool("trakt_sync_likedlists"),
 		TraktSyncPlaybackProgress:      settings.ToBool("trakt_sync_playback_progress"),
 		TraktSyncHidden:                settings.ToBool("trakt_sync_hidden"),
 		TraktSyncWatched:               settings.ToBool("trakt_sync_watched"),
@@ -759,6 +855,74 @@ func Reload() *Configuration {
 		newConfig.TraktSyncFrequencyMin = defaultTraktSyncFrequencyMin
 	}
 
+	// Collect liked Trakt lists enabled for sync
+	newConfig.TraktSyncLikedlistsIDs = []string{}
+	for _, id := range strings.Split(settings.ToString("trakt_sync_likedlists_ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			newConfig.TraktSyncLikedlistsIDs = append(newConfig.TraktSyncLikedlistsIDs, id)
+		}
+	}
+
+	// Collect per-list Trakt sync intervals
+	newConfig.TraktListIntervals = ParseListIntervals(settings.ToString("trakt_list_intervals"))
+
+	// Collect custom titles, used for library folder names
+	newConfig.MovieTitleAliases = ParseTitleAliases(settings.ToString("library_movie_aliases"))
+	newConfig.ShowTitleAliases = ParseTitleAliases(settings.ToString("library_show_aliases"))
+
+	// Collect per-show ranges of written seasons
+	newConfig.ShowSeasonRanges = ParseSeasonRanges(settings.ToString("library_show_season_ranges"))
+
+	// Collect additional library folders, paths are separated with "|" as they could contain commas
+	newConfig.LibraryExtraPaths = []string{}
+	for _, p := range strings.Split(settings.ToString("library_extra_paths"), "|") {
+		if p = strings.TrimSpace(p); p != "" && p != "." {
+			newConfig.LibraryExtraPaths = append(newConfig.LibraryExtraPaths, TranslatePath(p))
+		}
+	}
+
+	// Collect Kodi profiles, allowed to get library notifications
+	newConfig.NotifyProfiles = []string{}
+	for _, profile := range strings.Split(settings.ToString("library_notify_profiles"), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			newConfig.NotifyProfiles = append(newConfig.NotifyProfiles, profile)
+		}
+	}
+
+	newConfig.ExcludeGenres = []int{}
+	for _, genre := range strings.Split(settings.ToString("library_exclude_genres"), ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(genre)); err == nil && id > 0 {
+			newConfig.ExcludeGenres = append(newConfig.ExcludeGenres, id)
+		}
+	}
+
+	newConfig.AnimeAbsoluteShows = []int{}
+	for _, show := range strings.Split(settings.ToString("library_anime_absolute_shows"), ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(show)); err == nil && id > 0 {
+			newConfig.AnimeAbsoluteShows = append(newConfig.AnimeAbsoluteShows, id)
+		}
+	}
+
+	// Set default limit of subdirectories for library removals
+	if newConfig.LibraryRemoveMaxDirs == 0 {
+		newConfig.LibraryRemoveMaxDirs = defaultLibraryRemoveMaxDirs
+	}
+
+	// Set default number of attempts for library file writes
+	if newConfig.LibraryWriteAttempts <= 0 {
+		newConfig.LibraryWriteAttempts = defaultLibraryWriteAttempts
+	}
+
+	// Set default retention of removed library titles in trash
+	if newConfig.LibraryTrashDays == 0 {
+		newConfig.LibraryTrashDays = defaultLibraryTrashDays
+	}
+
+	// Set default naming of movie folders and strm files
+	if strings.TrimSpace(newConfig.MovieStrmTemplate) == "" {
+		newConfig.MovieStrmTemplate = defaultMovieStrmTemplate
+	}
+
 	// Setup OSDB language
 	if newConfig.OSDBAutoLanguage || newConfig.OSDBLanguage == "" {
 		newConfig.OSDBLanguage = newConfig.Language
@@ -825,6 +989,96 @@ func Reload() *Configuration {
 	return config
 }
 
+// ParseTitleAliases parses "<tmdb id>=<title>|<tmdb id>=<title>" setting value
+func ParseTitleAliases(value string) map[int]string {
+	ret := map[int]string{}
+	for _, pair := range strings.Split(value, "|") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		title := strings.TrimSpace(parts[1])
+		if err != nil || id == 0 || title == "" {
+			continue
+		}
+		ret[id] = title
+	}
+
+	return ret
+}
+
+// ParseListIntervals parses "<list id>=<minutes>|<list id>=<minutes>" setting value
+func ParseListIntervals(value string) map[string]int {
+	ret := map[string]int{}
+	for _, pair := range strings.Split(value, "|") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		listID := strings.TrimSpace(parts[0])
+		minutes, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || listID == "" || minutes <= 0 {
+			continue
+		}
+		ret[listID] = minutes
+	}
+
+	return ret
+}
+
+// ParseSeasonRanges parses "<show id>=<from>-<to>|<show id>=<from>-|<show id>=<season>" setting value,
+// range without upper bound has 0 as its end
+func ParseSeasonRanges(value string) map[int][2]int {
+	ret := map[int][2]int{}
+	for _, pair := range strings.Split(value, "|") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || id <= 0 {
+			continue
+		}
+
+		bounds := strings.SplitN(parts[1], "-", 2)
+		from, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil || from < 0 {
+			continue
+		}
+		to := from
+		if len(bounds) == 2 {
+			if end := strings.TrimSpace(bounds[1]); end == "" {
+				to = 0
+			} else if to, err = strconv.Atoi(end); err != nil || to < from {
+				continue
+			}
+		}
+		ret[id] = [2]int{from, to}
+	}
+
+	return ret
+}
+
+// FormatTitleAliases converts title aliases to the setting value, ordered by TMDB id
+func FormatTitleAliases(aliases map[int]string) string {
+	ids := make([]int, 0, len(aliases))
+	for id := range aliases {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	pairs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		pairs = append(pairs, fmt.Sprintf("%d=%s", id, strings.Replace(aliases[id], "|", "", -1)))
+	}
+
+	return strings.Join(pairs, "|")
+}
+
 // AddonIcon ...
 func AddonIcon() string {
 	return filepath.Join(Get().Info.Path, "icon.png")
@@ -1047,6 +1301,19 @@ func (s *XbmcSettings) ToInt64(key string) (ret int64) {
 	return
 }
 
+func (s *XbmcSettings) ToFloat32(key string) (ret float32) {
+	if _, ok := (*s)[key]; !ok {
+		log.Errorf("Setting '%s' not found!", key)
+		return 0
+	}
+
+	var err error
+	if ret, err = cast.ToFloat32E((*s)[key]); err != nil {
+		log.Errorf("Error casting property '%s' with value '%s' to 'float32': %s", key, (*s)[key], err)
+	}
+	return
+}
+
 func (s *XbmcSettings) ToBool(key string) (ret bool) {
 	if _, ok := (*s)[key]; !ok {
 		log.Errorf("Setting '%s' not found!", key)
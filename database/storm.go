@@ -29,6 +29,13 @@ func GetStormDB() *storm.DB {
 
 // InitStormDB ...
 func InitStormDB(conf *config.Configuration) (*StormDatabase, error) {
+	if conf.LibraryCompactDatabase {
+		// Database is compacted before anyone gets its handle, so it is safe to swap the file
+		if err := compactDatabaseFile(filepath.Join(conf.Info.Profile, stormFileName)); err != nil {
+			log.Warningf("Could not compact database: %s", err)
+		}
+	}
+
 	db, err := CreateStormDB(conf, stormFileName, backupStormFileName)
 	if err != nil || db == nil {
 		return nil, errors.New("database not created")
@@ -44,6 +51,63 @@ func InitStormDB(conf *config.Configuration) (*StormDatabase, error) {
 	return stormDatabase, nil
 }
 
+// compactDatabaseFile copies database into a new file without free pages and swaps it with the original one,
+// it should be called before database is opened. Compaction runs at most once per compactPeriod.
+func compactDatabaseFile(databasePath string) error {
+	markerPath := databasePath + ".compacted"
+	if stat, err := os.Stat(markerPath); err == nil && time.Since(stat.ModTime()) < compactPeriod {
+		return nil
+	}
+	if _, err := os.Stat(databasePath); err != nil {
+		return nil
+	}
+
+	defer perf.ScopeTimer()()
+
+	compactPath := databasePath + ".compact"
+	os.Remove(compactPath)
+
+	src, err := bolt.Open(databasePath, 0600, &bolt.Options{ReadOnly: true, Timeout: 15 * time.Second})
+	if err != nil {
+		return err
+	}
+	dst, err := bolt.Open(compactPath, 0600, &bolt.Options{Timeout: 15 * time.Second})
+	if err != nil {
+		src.Close()
+		return err
+	}
+
+	errCompact := bolt.Compact(dst, src, compactTxMaxSize)
+	src.Close()
+	if errClose := dst.Close(); errCompact == nil {
+		errCompact = errClose
+	}
+	if errCompact != nil {
+		os.Remove(compactPath)
+		return errCompact
+	}
+
+	var sizeBefore, sizeAfter int64
+	if stat, err := os.Stat(databasePath); err == nil {
+		sizeBefore = stat.Size()
+	}
+	if stat, err := os.Stat(compactPath); err == nil {
+		sizeAfter = stat.Size()
+	}
+
+	// Original file stays in place, if compacted one could not be moved
+	if err := os.Rename(compactPath, databasePath); err != nil {
+		os.Remove(compactPath)
+		return err
+	}
+	if f, err := os.Create(markerPath); err == nil {
+		f.Close()
+	}
+
+	log.Infof("Storm Database compacted from %d to %d bytes", sizeBefore, sizeAfter)
+	return nil
+}
+
 // CreateStormDB ...
 func CreateStormDB(conf *config.Configuration, fileName string, backupFileName string) (*storm.DB, error) {
 	databasePath := filepath.Join(conf.Info.Profile, fileName)
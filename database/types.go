@@ -65,6 +65,13 @@ type LibraryItem struct {
 	MediaType int `storm:"index"`
 	State     int `storm:"index"`
 	ShowID    int `storm:"index"`
+	Frozen    bool
+	AddedAt   time.Time
+	UpdatedAt time.Time
+	AddedBy   string `storm:"index"`
+	Origin    string `storm:"index"`
+	Title     string
+	Year      int
 }
 
 // QueryHistory ...
@@ -126,6 +133,11 @@ const (
 const (
 	historyMaxSize = 50
 	backupPeriod   = 5 * time.Hour
+
+	// compactTxMaxSize is a size of copied data, committed at once while compacting
+	compactTxMaxSize = 64 * 1024 * 1024
+	// compactPeriod is a minimal period between database compactions on startup
+	compactPeriod = 7 * 24 * time.Hour
 )
 
 var (
@@ -30,7 +30,6 @@ require (
 	github.com/jmcvetta/napping v3.2.0+incompatible
 	github.com/jmcvetta/randutil v0.0.0-20150817122601-2bb1b664bcff // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
-	github.com/karrick/godirwalk v1.16.1
 	github.com/klauspost/compress v1.13.6
 	github.com/kolo/xmlrpc v0.0.0-20201022064351-38db28db192b
 	github.com/likexian/doh-go v0.6.4
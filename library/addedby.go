@@ -0,0 +1,33 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/asdine/storm/q"
+
+	"github.com/elgatito/elementum/database"
+)
+
+// setAddedBy stores identity of the user, who added the library item
+func setAddedBy(tmdbID int, addedBy string) error {
+	li := getDBItem(database.GetStormDB(), tmdbID)
+	li.AddedBy = addedBy
+	return database.GetStormDB().Save(&li)
+}
+
+// addedByTag returns NFO tag with identity of the user, who added the item, if it is known
+func addedByTag(tmdbID int) string {
+	var li database.LibraryItem
+	if err := database.GetStormDB().One("ID", tmdbID, &li); err != nil || li.AddedBy == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+	<tag>%s</tag>`, escapeXML("Added by "+li.AddedBy))
+}
+
+// ListLibraryItemsAddedBy returns library items, added by the user,
+// -1 for mediaType or state matches any value
+func ListLibraryItemsAddedBy(mediaType int, state int, addedBy string) ([]database.LibraryItem, error) {
+	return listLibraryItems(mediaType, state, q.Eq("AddedBy", addedBy))
+}
@@ -0,0 +1,99 @@
+package library
+
+import (
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// movieTitle returns title used for movie folder and strm names
+func movieTitle(movie *tmdb.Movie) string {
+	if alias, ok := config.Get().MovieTitleAliases[movie.ID]; ok {
+		return alias
+	}
+
+	if config.Get().StrmLanguage != config.Get().Language && movie.Title != "" {
+		return movie.Title
+	}
+	return movie.OriginalTitle
+}
+
+// showTitle returns title used for show folder and strm names
+func showTitle(show *tmdb.Show) string {
+	if alias, ok := config.Get().ShowTitleAliases[show.ID]; ok {
+		return alias
+	}
+
+	if config.Get().StrmLanguage != config.Get().Language && show.Name != "" {
+		return show.Name
+	}
+	return show.OriginalName
+}
+
+// SetTitleAlias stores custom title for a movie or a show, empty title removes the alias.
+// Existing folders are not renamed, so alias should be set before adding the item.
+func SetTitleAlias(mediaType int, tmdbID int, title string) {
+	title = strings.TrimSpace(title)
+
+	var aliases map[int]string
+	var key string
+	if mediaType == MovieType {
+		aliases = config.Get().MovieTitleAliases
+		key = "library_movie_aliases"
+	} else {
+		aliases = config.Get().ShowTitleAliases
+		key = "library_show_aliases"
+	}
+
+	updated := make(map[int]string, len(aliases)+1)
+	for id, alias := range aliases {
+		if id != tmdbID {
+			updated[id] = alias
+		}
+	}
+	if title != "" {
+		updated[tmdbID] = title
+	}
+
+	if mediaType == MovieType {
+		config.Get().MovieTitleAliases = updated
+	} else {
+		config.Get().ShowTitleAliases = updated
+	}
+	xbmc.SetSetting(key, config.FormatTitleAliases(updated))
+}
+
+// movieAlternativeTitles returns TMDB alternative titles of the movie, that differ from known titles
+func movieAlternativeTitles(movie *tmdb.Movie, known []string) []string {
+	if movie.AlternativeTitles == nil {
+		return nil
+	}
+	return alternativeTitles(movie.AlternativeTitles.Titles, known)
+}
+
+// showAlternativeTitles returns TMDB alternative titles of the show, that differ from known titles
+func showAlternativeTitles(show *tmdb.Show, known []string) []string {
+	if show.AlternativeTitles == nil {
+		return nil
+	}
+	return alternativeTitles(show.AlternativeTitles.Titles, known)
+}
+
+func alternativeTitles(titles []*tmdb.AlternativeTitle, known []string) []string {
+	seen := map[string]bool{}
+	for _, t := range known {
+		seen[t] = true
+	}
+
+	ret := []string{}
+	for _, t := range titles {
+		if t == nil || t.Title == "" || seen[t.Title] {
+			continue
+		}
+		seen[t.Title] = true
+		ret = append(ret, t.Title)
+	}
+	return ret
+}
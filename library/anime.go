@@ -0,0 +1,109 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/tvdb"
+	"github.com/elgatito/elementum/util"
+)
+
+// isAnimeAbsolute checks if show episodes should be named by absolute episode number,
+// either the show is listed in config, or detection by TMDB genre and country is enabled
+func isAnimeAbsolute(show *tmdb.Show) bool {
+	for _, id := range config.Get().AnimeAbsoluteShows {
+		if id == show.ID {
+			return true
+		}
+	}
+
+	return config.Get().AnimeAbsoluteNumbering && show.IsAnime()
+}
+
+// showAbsoluteNumbers returns resolver of absolute episode numbers of the show.
+// TVDB absolute numbers are preferred, otherwise episodes of previous seasons are counted.
+// Specials have no absolute number, so resolver returns 0 for them.
+func showAbsoluteNumbers(show *tmdb.Show) func(season, episode int) int {
+	var tvdbShow *tvdb.Show
+	if show.ExternalIDs != nil {
+		if tvdbID := util.StrInterfaceToInt(show.ExternalIDs.TVDBID); tvdbID != 0 {
+			tvdbShow, _ = tvdb.GetShow(tvdbID, config.Get().Language)
+		}
+	}
+
+	return func(season, episode int) int {
+		if season == 0 {
+			return 0
+		}
+
+		if tvdbShow != nil {
+			if an, _ := show.AnimeInfoWithShow(&tmdb.Episode{SeasonNumber: season, EpisodeNumber: episode}, tvdbShow); an > 0 {
+				return an
+			}
+		}
+
+		an := episode
+		for _, s := range show.Seasons {
+			if s != nil && s.Season > 0 && s.Season < season {
+				an += s.EpisodeCount
+			}
+		}
+		return an
+	}
+}
+
+// episodeNumber is season and episode number of an episode
+type episodeNumber struct {
+	Season  int
+	Episode int
+}
+
+// absoluteEpisodes maps absolute codes of episode strm files back to season and episode numbers,
+// so anime episodes are pruned by season like the ones named by SxxExx, nil resolver gives nil map
+func absoluteEpisodes(show *tmdb.Show, absolute func(season, episode int) int) map[string]episodeNumber {
+	if absolute == nil {
+		return nil
+	}
+
+	ret := map[string]episodeNumber{}
+	for _, s := range show.Seasons {
+		if s == nil || s.Season == 0 {
+			continue
+		}
+		for episode := 1; episode <= s.EpisodeCount; episode++ {
+			if an := absolute(s.Season, episode); an > 0 {
+				ret[absoluteCode(an)] = episodeNumber{Season: s.Season, Episode: episode}
+			}
+		}
+	}
+	return ret
+}
+
+// showAbsoluteEpisodes returns absolute codes of the show episodes, if show is named by absolute numbers
+func showAbsoluteEpisodes(show *tmdb.Show) map[string]episodeNumber {
+	if !isAnimeAbsolute(show) {
+		return nil
+	}
+	return absoluteEpisodes(show, showAbsoluteNumbers(show))
+}
+
+// codeEpisode returns season and episode number of episode strm file code, either SxxExx or absolute one
+func codeEpisode(code string, absolutes map[string]episodeNumber) (season, episode int, ok bool) {
+	if n, found := absolutes[code]; found {
+		return n.Season, n.Episode, true
+	}
+
+	n, _ := fmt.Sscanf(code, "S%dE%d", &season, &episode)
+	return season, episode, n == 2
+}
+
+// absoluteCode returns key of episode strm file, named by absolute number
+func absoluteCode(an int) string {
+	return fmt.Sprintf("#%d", an)
+}
+
+// animeStrmName returns episode strm file name with absolute episode number
+func animeStrmName(showStrm string, an int) string {
+	return fmt.Sprintf("%s - %d.strm", showStrm, an)
+}
@@ -0,0 +1,55 @@
+package library
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/elgatito/elementum/tmdb"
+)
+
+func TestSeasonsBeforeAbsolute(t *testing.T) {
+	show := &tmdb.Show{Seasons: tmdb.SeasonList{
+		{Season: 0, EpisodeCount: 2},
+		{Season: 1, EpisodeCount: 12},
+		{Season: 2, EpisodeCount: 12},
+		{Season: 3, EpisodeCount: 10},
+	}}
+	absolutes := absoluteEpisodes(show, func(season, episode int) int {
+		return (season-1)*12 + episode
+	})
+
+	existing := map[string][]string{
+		"#5":     {"Show - 5.strm"},
+		"#13":    {"Show - 13.strm"},
+		"#30":    {"Show - 30.strm"},
+		"#99":    {"Show - 99.strm"},
+		"S00E01": {"Show S00E01.strm"},
+		"S01E02": {"Show S01E02.strm"},
+		"S03E01": {"Show S03E01.strm"},
+	}
+
+	got := []string{}
+	for code := range seasonsBefore(existing, 3, absolutes) {
+		got = append(got, code)
+	}
+	sort.Strings(got)
+	if want := []string{"#13", "#5", "S01E02"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("seasonsBefore() = %v, want %v", got, want)
+	}
+
+	removed := map[int][]int{}
+	for _, code := range got {
+		addRemovedCode(removed, code, absolutes)
+	}
+	for _, episodes := range removed {
+		sort.Ints(episodes)
+	}
+	if want := map[int][]int{1: {2, 5}, 2: {1}}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed episodes = %v, want %v", removed, want)
+	}
+
+	if _, _, ok := codeEpisode("#99", absolutes); ok {
+		t.Errorf("codeEpisode(#99) is resolved, though there is no such episode")
+	}
+}
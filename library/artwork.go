@@ -0,0 +1,114 @@
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/elgatito/elementum/tmdb"
+)
+
+const (
+	artworkSize = "original"
+	// artworkWorkers is a number of artwork files, downloaded at once
+	artworkWorkers = 3
+)
+
+// movieArtwork returns artwork files to download into movie folder
+func movieArtwork(movie *tmdb.Movie) map[string]string {
+	return map[string]string{
+		"poster.jpg": movie.PosterPath,
+		"fanart.jpg": movie.BackdropPath,
+	}
+}
+
+// showArtwork returns artwork files to download into show folder, including season posters
+func showArtwork(show *tmdb.Show) map[string]string {
+	ret := map[string]string{
+		"poster.jpg": show.PosterPath,
+		"fanart.jpg": show.BackdropPath,
+	}
+
+	for _, season := range show.Seasons {
+		if season == nil || season.Poster == "" {
+			continue
+		}
+
+		if season.Season == 0 {
+			ret["season-specials-poster.jpg"] = season.Poster
+		} else {
+			ret[fmt.Sprintf("season%02d-poster.jpg", season.Season)] = season.Poster
+		}
+	}
+
+	return ret
+}
+
+// prefixArtwork prefixes artwork file names with title name, for titles without own folder
+func prefixArtwork(prefix string, files map[string]string) map[string]string {
+	ret := make(map[string]string, len(files))
+	for name, uri := range files {
+		ret[prefix+"-"+name] = uri
+	}
+	return ret
+}
+
+// writeArtwork downloads artwork into the title folder, skipping files that already exist,
+// returns paths of written files
+func writeArtwork(dir string, files map[string]string) (written []string) {
+	type artworkJob struct {
+		path string
+		uri  string
+	}
+
+	jobs := []artworkJob{}
+	for name, uri := range files {
+		if uri == "" {
+			continue
+		}
+
+		p := filepath.Join(dir, name)
+		if st, err := libFS.Stat(p); err == nil && st.Size() > 0 {
+			continue
+		}
+		jobs = append(jobs, artworkJob{path: p, uri: uri})
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	queue := make(chan artworkJob, len(jobs))
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i := 0; i < artworkWorkers && i < len(jobs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for job := range queue {
+				data, err := tmdb.DownloadImage(job.uri, artworkSize)
+				if err != nil || len(data) == 0 {
+					log.Warningf("Could not download artwork %s: %v", job.path, err)
+					continue
+				}
+
+				if err := libFS.WriteFile(job.path, data, 0644); err != nil {
+					log.Warningf("Could not write artwork %s: %s", job.path, err)
+					continue
+				}
+
+				mu.Lock()
+				written = append(written, job.path)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	return
+}
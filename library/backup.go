@@ -0,0 +1,118 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/database"
+)
+
+// ExportLibrary writes all library items into JSON file
+func ExportLibrary(path string) error {
+	lis, err := ListLibraryItems(-1, -1)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(&LibraryExport{
+		SchemaVersion: librarySchemaVersion,
+		ExportedAt:    time.Now(),
+		Items:         lis,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := libFS.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	log.Infof("Exported %d library items to %s", len(lis), path)
+	return nil
+}
+
+// ImportLibrary restores library items from JSON file, written by ExportLibrary.
+// Items are saved in a single transaction, and strm files of active movies and shows
+// are written again, if regenerateStrm is set.
+func ImportLibrary(path string, regenerateStrm bool) error {
+	data, err := libFS.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	export := &LibraryExport{}
+	if err := json.Unmarshal(data, export); err != nil {
+		return err
+	}
+	if export.SchemaVersion > librarySchemaVersion {
+		return fmt.Errorf("Library export has newer schema version %d", export.SchemaVersion)
+	}
+
+	tx, err := database.GetStormDB().Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, li := range export.Items {
+		if li.ID == 0 {
+			continue
+		}
+		if err := tx.Save(&li); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	// Older exports are brought to the current schema
+	if export.SchemaVersion < librarySchemaVersion {
+		if cacheStore == nil {
+			InitDB()
+		}
+		cacheStore.Set(cache.LibrarySchemaVersionKey, export.SchemaVersion, cache.LibrarySchemaVersionExpire)
+		if err := MigrateSchema(); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Imported %d library items from %s", len(export.Items), path)
+	if !regenerateStrm {
+		return nil
+	}
+
+	failed := 0
+	for _, li := range export.Items {
+		if closer.IsSet() {
+			return ErrLibraryClosing
+		}
+		if li.State != StateActive {
+			continue
+		}
+
+		switch li.MediaType {
+		case MovieType:
+			_, _, err = writeMovieStrm(strconv.Itoa(li.ID), false)
+		case ShowType:
+			_, _, _, err = writeShowStrm(li.ID, false, false)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Warningf("Could not write strm files for %d: %s", li.ID, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("Could not write strm files for %d items", failed)
+	}
+
+	RequestScan("")
+	return nil
+}
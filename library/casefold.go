@@ -0,0 +1,206 @@
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const caseCheckFile = ".elementum-case-check"
+
+// caseInsensitiveFS is set when library is located on a filesystem,
+// that does not distinguish file names by case (macOS default, some SMB shares)
+var caseInsensitiveFS = false
+
+var (
+	folderIndexMu sync.Mutex
+	// folderIndexUsers counts running syncs and updates, folder name indexes are kept while they run
+	folderIndexUsers int
+	// folderIndexes lists folder names of library roots by their lower-cased name
+	folderIndexes = map[string]map[string][]string{}
+)
+
+// beginFolderIndex keeps folder name indexes of library roots, until returned function is called,
+// so case-insensitive lookups do not read the root for each title
+func beginFolderIndex() func() {
+	folderIndexMu.Lock()
+	folderIndexUsers++
+	folderIndexMu.Unlock()
+
+	return func() {
+		folderIndexMu.Lock()
+		defer folderIndexMu.Unlock()
+
+		if folderIndexUsers--; folderIndexUsers == 0 {
+			folderIndexes = map[string]map[string][]string{}
+		}
+	}
+}
+
+// foldersByLowerName returns folders of the root, matching the name case-insensitively
+func foldersByLowerName(root, name string) []string {
+	folderIndexMu.Lock()
+	defer folderIndexMu.Unlock()
+
+	index, ok := folderIndexes[root]
+	if !ok {
+		index = map[string][]string{}
+		if entries, err := libFS.ReadDir(root); err == nil {
+			for _, e := range entries {
+				if e.IsDir() {
+					lower := strings.ToLower(e.Name())
+					index[lower] = append(index[lower], e.Name())
+				}
+			}
+		}
+		if folderIndexUsers == 0 {
+			return index[strings.ToLower(name)]
+		}
+		folderIndexes[root] = index
+	}
+
+	// Index could keep folders, removed since it was built
+	ret := []string{}
+	for _, n := range index[strings.ToLower(name)] {
+		if _, err := libFS.Stat(filepath.Join(root, n)); err == nil {
+			ret = append(ret, n)
+		}
+	}
+	return ret
+}
+
+// rememberFolder adds folder, that is about to be created, to the name index of the root
+func rememberFolder(root, name string) {
+	folderIndexMu.Lock()
+	defer folderIndexMu.Unlock()
+
+	index, ok := folderIndexes[root]
+	if !ok {
+		return
+	}
+
+	lower := strings.ToLower(name)
+	for _, n := range index[lower] {
+		if n == name {
+			return
+		}
+	}
+	index[lower] = append(index[lower], name)
+}
+
+// detectCaseInsensitiveFS checks library filesystem case sensitivity
+func detectCaseInsensitiveFS(dir string) bool {
+	p := filepath.Join(dir, caseCheckFile)
+	if err := libFS.WriteFile(p, []byte{}, 0644); err != nil {
+		log.Warningf("Could not check filesystem case sensitivity: %s", err)
+		return false
+	}
+	defer libFS.Remove(p)
+
+	_, err := libFS.Stat(filepath.Join(dir, strings.ToUpper(caseCheckFile)))
+	return err == nil
+}
+
+// disambiguateFolder returns folder name for a title. On case-insensitive filesystem,
+// when other title already uses folder with the same name in different case,
+// TMDB id is appended to the name to avoid mixing files of both titles.
+func disambiguateFolder(root, name string, mediaType, tmdbID int) string {
+	if !caseInsensitiveFS {
+		return name
+	}
+
+	for _, existing := range foldersByLowerName(root, name) {
+		if existing == name {
+			continue
+		}
+
+		if folderBelongsTo(filepath.Join(root, existing), mediaType, tmdbID) {
+			// Same title, case changed on TMDB side, so keep using existing folder
+			return existing
+		}
+
+		log.Infof("Folder %s is used by other title, disambiguating %s with TMDB id", existing, name)
+		name = fmt.Sprintf("%s [%d]", name, tmdbID)
+		break
+	}
+
+	rememberFolder(root, name)
+	return name
+}
+
+// folderBelongsTo checks that strm files in the folder, or in its season folders, point to the title
+func folderBelongsTo(dir string, mediaType, tmdbID int) bool {
+	entries, err := libFS.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	seasons := []string{}
+	for _, e := range entries {
+		if e.IsDir() && isSeasonFolder(e.Name()) {
+			seasons = append(seasons, filepath.Join(dir, e.Name()))
+			continue
+		} else if e.IsDir() || !strings.HasSuffix(e.Name(), ".strm") {
+			continue
+		}
+
+		content, err := libFS.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+
+		link, err := ResolvePlayLink(string(content))
+		if err != nil {
+			continue
+		}
+
+		linkType := link.MediaType
+		if linkType == EpisodeType {
+			linkType = ShowType
+		}
+		return linkType == mediaType && link.TMDBID == tmdbID
+	}
+
+	for _, season := range seasons {
+		if !folderBelongsTo(season, mediaType, tmdbID) {
+			return false
+		}
+	}
+
+	// Folder without strm files is not taken by anyone
+	return true
+}
+
+// findTitleFolders returns existing folders of the title, matching folder names
+// case-insensitively on case-insensitive filesystems, including disambiguated names
+func findTitleFolders(root string, names []string, mediaType, tmdbID int) map[string]bool {
+	ret := map[string]bool{}
+
+	candidates := map[string]bool{}
+	for _, name := range names {
+		candidates[name] = true
+		candidates[fmt.Sprintf("%s [%d]", name, tmdbID)] = true
+	}
+
+	if !caseInsensitiveFS {
+		for name := range candidates {
+			p := filepath.Join(root, name)
+			if _, err := libFS.Stat(p); err == nil {
+				ret[p] = true
+			}
+		}
+		return ret
+	}
+
+	for name := range candidates {
+		for _, existing := range foldersByLowerName(root, name) {
+			p := filepath.Join(root, existing)
+			if folderBelongsTo(p, mediaType, tmdbID) {
+				ret[p] = true
+			}
+		}
+	}
+
+	return ret
+}
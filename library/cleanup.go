@@ -0,0 +1,69 @@
+package library
+
+import (
+	"sort"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// PlanCleanup collects paths, that would be removed by cleanup passes,
+// without touching the disk or the database
+func PlanCleanup() (*CleanupPlan, error) {
+	plan := &CleanupPlan{}
+
+	var err error
+	if plan.EmptyDirs, err = CleanupEmptyDirectories(true); err != nil {
+		return nil, err
+	}
+	if plan.Orphaned, err = RemoveOrphanedPaths(true); err != nil {
+		return nil, err
+	}
+	if plan.PrunedSeasons, err = PruneSeasons(true); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// PruneSeasons removes episodes of seasons, that went out of kept seasons window,
+// with dryRun set files are only reported
+func PruneSeasons(dryRun bool) ([]string, error) {
+	ret := []string{}
+
+	keep := config.Get().KeepLastNSeasons
+	if keep <= 0 {
+		return ret, nil
+	}
+
+	lis, err := ListLibraryItems(ShowType, StateActive)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, li := range lis {
+		if closer.IsSet() {
+			return ret, ErrLibraryClosing
+		}
+
+		show := tmdb.GetShow(li.ID, config.Get().StrmLanguage)
+		if show == nil {
+			continue
+		}
+
+		showPath, showStrm := getShowPath(show)
+		existing := episodeStrmFiles(showPath, showStrm)
+		minSeason := firstKeptSeason(show, keep)
+		absolutes := showAbsoluteEpisodes(show)
+		for _, paths := range seasonsBefore(existing, minSeason, absolutes) {
+			ret = append(ret, paths...)
+		}
+
+		if !dryRun {
+			removeSeasonsBefore(show, existing, minSeason, absolutes)
+		}
+	}
+
+	sort.Strings(ret)
+	return ret, nil
+}
@@ -0,0 +1,51 @@
+package library
+
+import (
+	"sync"
+)
+
+// eventBufferSize is a number of events, subscriber could lag behind before events are dropped for it
+const eventBufferSize = 32
+
+var (
+	subscribersMu sync.RWMutex
+	subscribers   = map[<-chan LibraryEvent]chan LibraryEvent{}
+)
+
+// Subscribe returns channel, receiving library changes, made by add and remove functions.
+// Events are dropped for subscriber, that does not read them fast enough.
+// Channel should be released with Unsubscribe.
+func Subscribe() <-chan LibraryEvent {
+	ch := make(chan LibraryEvent, eventBufferSize)
+
+	subscribersMu.Lock()
+	subscribers[ch] = ch
+	subscribersMu.Unlock()
+
+	return ch
+}
+
+// Unsubscribe stops delivery of library changes and closes the channel
+func Unsubscribe(ch <-chan LibraryEvent) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+
+	if c, ok := subscribers[ch]; ok {
+		delete(subscribers, ch)
+		close(c)
+	}
+}
+
+// publishEvent passes event to all subscribers without blocking
+func publishEvent(e LibraryEvent) {
+	subscribersMu.RLock()
+	defer subscribersMu.RUnlock()
+
+	for _, c := range subscribers {
+		select {
+		case c <- e:
+		default:
+			log.Debugf("Subscriber is not reading library events, dropping %s event for %d", e.Action, e.TMDBID)
+		}
+	}
+}
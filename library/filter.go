@@ -0,0 +1,92 @@
+package library
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+var (
+	skippedMu      sync.Mutex
+	skippedImports = map[string]SkippedImport{}
+)
+
+// hasImportFilter checks if list imports are limited by any content filter
+func hasImportFilter() bool {
+	return hasRatingFilter() || len(config.Get().ExcludeGenres) > 0
+}
+
+// excludedGenre returns name of the first genre, excluded from imports
+func excludedGenre(genres []*tmdb.IDName) (string, bool) {
+	for _, g := range genres {
+		if g == nil {
+			continue
+		}
+		for _, id := range config.Get().ExcludeGenres {
+			if g.ID == id {
+				return g.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// movieSkipReason returns reason for the movie to be skipped during list import, or empty string
+func movieSkipReason(m *tmdb.Movie) string {
+	if m == nil {
+		return "no TMDB details"
+	}
+	if !passesRatingFilter(m.VoteAverage, m.VoteCount) {
+		return "low rating or votes count"
+	}
+	if name, ok := excludedGenre(m.Genres); ok {
+		return fmt.Sprintf("excluded genre %s", name)
+	}
+	return ""
+}
+
+// showSkipReason returns reason for the show to be skipped during list import, or empty string
+func showSkipReason(s *tmdb.Show) string {
+	if s == nil {
+		return "no TMDB details"
+	}
+	if !passesRatingFilter(s.VoteAverage, s.VoteCount) {
+		return "low rating or votes count"
+	}
+	if name, ok := excludedGenre(s.Genres); ok {
+		return fmt.Sprintf("excluded genre %s", name)
+	}
+	return ""
+}
+
+func recordSkip(mediaType, tmdbID int, title, reason string) {
+	log.Debugf("Skipping %s: %s", title, reason)
+
+	skippedMu.Lock()
+	defer skippedMu.Unlock()
+
+	skippedImports[fmt.Sprintf("%d_%d", mediaType, tmdbID)] = SkippedImport{
+		MediaType: mediaType,
+		TMDBID:    tmdbID,
+		Title:     title,
+		Reason:    reason,
+		SkippedAt: time.Now(),
+	}
+}
+
+// SkippedImports returns list items, skipped by content filters since start, most recent first
+func SkippedImports() []SkippedImport {
+	skippedMu.Lock()
+	ret := make([]SkippedImport, 0, len(skippedImports))
+	for _, s := range skippedImports {
+		ret = append(ret, s)
+	}
+	skippedMu.Unlock()
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].SkippedAt.After(ret[j].SkippedAt) })
+	return ret
+}
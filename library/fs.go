@@ -0,0 +1,378 @@
+package library
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// writeRetryDelay is a delay before the second write attempt, doubled for each next one
+const writeRetryDelay = 500 * time.Millisecond
+
+// FileSystem describes filesystem operations used by library writers and removers
+type FileSystem interface {
+	WriteFile(name string, data []byte, perm os.FileMode) error
+	ReadFile(name string) ([]byte, error)
+	Mkdir(name string, perm os.FileMode) error
+	Stat(name string) (os.FileInfo, error)
+	Remove(name string) error
+	RemoveAll(path string) error
+	ReadDir(dirname string) ([]os.FileInfo, error)
+	Rename(oldpath, newpath string) error
+	Chtimes(name string, atime time.Time, mtime time.Time) error
+}
+
+// libFS is a filesystem, library files are written to and removed from
+var libFS FileSystem = osFS{}
+
+// SetFileSystem replaces filesystem used by the library, returns previous one
+func SetFileSystem(f FileSystem) FileSystem {
+	previous := libFS
+	libFS = f
+	return previous
+}
+
+// writeFileRetry writes library file, retrying transient errors of network filesystems,
+// permanent errors, like missing permissions or free space, are returned right away
+func writeFileRetry(name string, data []byte, perm os.FileMode) (err error) {
+	delay := writeRetryDelay
+	attempts := config.Get().LibraryWriteAttempts
+	for attempt := 1; ; attempt++ {
+		if err = libFS.WriteFile(name, data, perm); err == nil || attempt >= attempts || !isTransientWriteError(err) {
+			return
+		}
+
+		log.Debugf("Retrying write of %s after transient error: %s", name, err)
+		select {
+		case <-closer.C():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isUnchangedFile checks if file already has the content, so it does not need to be rewritten
+func isUnchangedFile(name string, data []byte) bool {
+	content, err := libFS.ReadFile(name)
+	return err == nil && bytes.Equal(content, data)
+}
+
+// touchPath updates modification time of the path, so forced rewrite, that kept all files unchanged,
+// is still noticed by Kodi
+func touchPath(path string) {
+	now := time.Now().Local()
+	if err := libFS.Chtimes(path, now, now); err != nil {
+		log.Debugf("Could not update modification time of %s: %s", path, err)
+	}
+}
+
+// isTransientWriteError checks if failed write could succeed on retry
+func isTransientWriteError(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+
+	switch errno {
+	case syscall.EAGAIN, syscall.EINTR, syscall.ETXTBSY, syscall.ESTALE, syscall.EBUSY, syscall.EIO:
+		return true
+	case syscall.ENOSPC, syscall.EACCES, syscall.EPERM, syscall.EROFS, syscall.EDQUOT:
+		return false
+	}
+	return errno.Temporary() || errno.Timeout()
+}
+
+//
+// OS filesystem
+//
+
+type osFS struct{}
+
+func (osFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return ioutil.WriteFile(name, data, perm)
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+func (osFS) Mkdir(name string, perm os.FileMode) error {
+	return os.Mkdir(name, perm)
+}
+
+func (osFS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFS) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (osFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(dirname)
+}
+
+func (osFS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+//
+// In-memory filesystem
+//
+
+// MemFS is an in-memory FileSystem, useful to run library code without touching disk
+type MemFS struct {
+	mu    sync.RWMutex
+	nodes map[string]*memNode
+}
+
+type memNode struct {
+	name    string
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (n *memNode) Name() string       { return n.name }
+func (n *memNode) Size() int64        { return int64(len(n.data)) }
+func (n *memNode) Mode() os.FileMode  { return n.mode }
+func (n *memNode) ModTime() time.Time { return n.modTime }
+func (n *memNode) IsDir() bool        { return n.mode.IsDir() }
+func (n *memNode) Sys() interface{}   { return nil }
+
+// NewMemFS returns empty in-memory filesystem
+func NewMemFS() *MemFS {
+	return &MemFS{nodes: map[string]*memNode{}}
+}
+
+// MkdirAll creates directory with all missing parents
+func (m *MemFS) MkdirAll(path string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	for _, p := range memParents(path) {
+		if n, ok := m.nodes[p]; ok {
+			if !n.IsDir() {
+				return &os.PathError{Op: "mkdir", Path: p, Err: os.ErrExist}
+			}
+			continue
+		}
+		m.nodes[p] = &memNode{name: filepath.Base(p), mode: os.ModeDir | perm, modTime: time.Now()}
+	}
+
+	return nil
+}
+
+// WriteFile writes data to the file, replacing its content, parent directory should exist
+func (m *MemFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if !m.hasDir(filepath.Dir(name)) {
+		return &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	if n, ok := m.nodes[name]; ok && n.IsDir() {
+		return &os.PathError{Op: "open", Path: name, Err: os.ErrExist}
+	}
+
+	m.nodes[name] = &memNode{name: filepath.Base(name), data: append([]byte{}, data...), mode: perm, modTime: time.Now()}
+	return nil
+}
+
+// ReadFile returns a copy of file content
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n, ok := m.nodes[filepath.Clean(name)]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	} else if n.IsDir() {
+		return nil, &os.PathError{Op: "read", Path: name, Err: os.ErrInvalid}
+	}
+
+	return append([]byte{}, n.data...), nil
+}
+
+// Mkdir creates directory, parent directory should exist and the path should not
+func (m *MemFS) Mkdir(name string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if _, ok := m.nodes[name]; ok {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrExist}
+	} else if !m.hasDir(filepath.Dir(name)) {
+		return &os.PathError{Op: "mkdir", Path: name, Err: os.ErrNotExist}
+	}
+
+	m.nodes[name] = &memNode{name: filepath.Base(name), mode: os.ModeDir | perm, modTime: time.Now()}
+	return nil
+}
+
+// Stat returns file or directory info, filesystem root always exists
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	name = filepath.Clean(name)
+	if isMemRoot(name) {
+		return &memNode{name: name, mode: os.ModeDir | 0755}, nil
+	}
+	if n, ok := m.nodes[name]; ok {
+		return n, nil
+	}
+
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+// Remove removes file or empty directory
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	n, ok := m.nodes[name]
+	if !ok {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrNotExist}
+	}
+	if n.IsDir() && len(m.children(name)) > 0 {
+		return &os.PathError{Op: "remove", Path: name, Err: os.ErrInvalid}
+	}
+
+	delete(m.nodes, name)
+	return nil
+}
+
+// RemoveAll removes path with everything under it, missing path is not an error
+func (m *MemFS) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	prefix := path + string(filepath.Separator)
+	for p := range m.nodes {
+		if p == path || strings.HasPrefix(p, prefix) {
+			delete(m.nodes, p)
+		}
+	}
+
+	return nil
+}
+
+// ReadDir returns entries of the directory, sorted by name
+func (m *MemFS) ReadDir(dirname string) ([]os.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	dirname = filepath.Clean(dirname)
+	if !m.hasDir(dirname) {
+		return nil, &os.PathError{Op: "open", Path: dirname, Err: os.ErrNotExist}
+	}
+
+	ret := []os.FileInfo{}
+	for _, p := range m.children(dirname) {
+		ret = append(ret, m.nodes[p])
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name() < ret[j].Name() })
+
+	return ret, nil
+}
+
+// Rename moves file or directory with everything under it, replacing existing file at newpath
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldpath = filepath.Clean(oldpath)
+	newpath = filepath.Clean(newpath)
+	if _, ok := m.nodes[oldpath]; !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrNotExist}
+	} else if !m.hasDir(filepath.Dir(newpath)) {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrNotExist}
+	}
+
+	moved := map[string]*memNode{}
+	prefix := oldpath + string(filepath.Separator)
+	for p, n := range m.nodes {
+		if p == oldpath {
+			n.name = filepath.Base(newpath)
+			moved[newpath] = n
+		} else if strings.HasPrefix(p, prefix) {
+			moved[newpath+string(filepath.Separator)+strings.TrimPrefix(p, prefix)] = n
+		} else {
+			continue
+		}
+		delete(m.nodes, p)
+	}
+	for p, n := range moved {
+		m.nodes[p] = n
+	}
+
+	return nil
+}
+
+// Chtimes changes modification time of the file or directory, access time is not tracked
+func (m *MemFS) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n, ok := m.nodes[filepath.Clean(name)]
+	if !ok {
+		return &os.PathError{Op: "chtimes", Path: name, Err: os.ErrNotExist}
+	}
+
+	n.modTime = mtime
+	return nil
+}
+
+func (m *MemFS) hasDir(path string) bool {
+	if isMemRoot(path) {
+		return true
+	}
+
+	n, ok := m.nodes[path]
+	return ok && n.IsDir()
+}
+
+func (m *MemFS) children(dir string) (ret []string) {
+	for p := range m.nodes {
+		if p != dir && filepath.Dir(p) == dir {
+			ret = append(ret, p)
+		}
+	}
+	return
+}
+
+func isMemRoot(path string) bool {
+	return path == "." || filepath.Dir(path) == path
+}
+
+// memParents returns path with all its parents, starting from the top one
+func memParents(path string) (ret []string) {
+	for !isMemRoot(path) {
+		ret = append([]string{path}, ret...)
+		path = filepath.Dir(path)
+	}
+	return
+}
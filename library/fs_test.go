@@ -0,0 +1,119 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+)
+
+// setupMemLibrary switches library to an empty in-memory filesystem with library path in /library,
+// previous filesystem and settings are restored when the test finishes
+func setupMemLibrary(t *testing.T) *MemFS {
+	m := NewMemFS()
+	previous := SetFileSystem(m)
+
+	conf := config.Get()
+	saved := *conf
+	conf.LibraryPath = "/library"
+	conf.LibraryExtraPaths = nil
+
+	for _, p := range []string{MoviesLibraryPaths()[0], ShowsLibraryPaths()[0]} {
+		if err := m.MkdirAll(p, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Cleanup(func() {
+		*conf = saved
+		SetFileSystem(previous)
+	})
+	return m
+}
+
+// writeMemFiles creates files with their parent folders
+func writeMemFiles(t *testing.T, m *MemFS, files ...string) {
+	for _, f := range files {
+		if err := m.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.WriteFile(f, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// assertMemFiles checks existence of files
+func assertMemFiles(t *testing.T, m *MemFS, exist bool, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		if _, err := m.Stat(f); (err == nil) != exist {
+			t.Errorf("%s: exists = %v, want %v", f, err == nil, exist)
+		}
+	}
+}
+
+func TestMemFS(t *testing.T) {
+	m := NewMemFS()
+
+	if err := m.WriteFile("/a/file.strm", []byte("link"), 0644); !os.IsNotExist(err) {
+		t.Errorf("WriteFile() without parent = %v, want not exist error", err)
+	}
+	if err := m.Mkdir("/a", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Mkdir("/a", 0755); !os.IsExist(err) {
+		t.Errorf("Mkdir() of existing folder = %v, want exist error", err)
+	}
+	writeMemFiles(t, m, "/a/b.strm", "/a/a.strm", "/a/sub/c.strm")
+
+	data, err := m.ReadFile("/a/b.strm")
+	if err != nil || string(data) != "/a/b.strm" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+	// Returned content is a copy, so callers could not change the file
+	data[0] = 'x'
+	if again, _ := m.ReadFile("/a/b.strm"); string(again) != "/a/b.strm" {
+		t.Errorf("ReadFile() after changing returned data = %q", again)
+	}
+
+	entries, err := m.ReadDir("/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"a.strm", "b.strm", "sub"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ReadDir() = %v, want %v", names, want)
+	}
+
+	if err := m.Remove("/a/sub"); err == nil {
+		t.Errorf("Remove() of non-empty folder succeeded")
+	}
+
+	mtime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := m.Chtimes("/a/sub", mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Rename("/a/sub", "/a/moved"); err != nil {
+		t.Fatal(err)
+	}
+	assertMemFiles(t, m, false, "/a/sub", "/a/sub/c.strm")
+	assertMemFiles(t, m, true, "/a/moved", "/a/moved/c.strm")
+	if st, err := m.Stat("/a/moved"); err != nil || !st.IsDir() || !st.ModTime().Equal(mtime) || st.Name() != "moved" {
+		t.Errorf("Stat() of renamed folder = %+v, %v", st, err)
+	}
+
+	if err := m.RemoveAll("/a"); err != nil {
+		t.Fatal(err)
+	}
+	assertMemFiles(t, m, false, "/a", "/a/a.strm", "/a/moved/c.strm")
+	if _, err := m.Stat("/"); err != nil {
+		t.Errorf("Stat() of root = %v", err)
+	}
+}
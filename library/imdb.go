@@ -0,0 +1,68 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// ErrIMDBNotResolved is returned when IMDB id has no matching TMDB title
+var ErrIMDBNotResolved = errors.New("Could not resolve IMDB id to TMDB id")
+
+// AddMovieByIMDB adds movie to the library by its IMDB id
+func AddMovieByIMDB(imdbID string, force bool) (*tmdb.Movie, error) {
+	tmdbID, err := resolveIMDB(imdbID, MovieType)
+	if err != nil {
+		return nil, err
+	}
+
+	return AddMovie(strconv.Itoa(tmdbID), force, "")
+}
+
+// RemoveMovieByIMDB removes movie from the library by its IMDB id
+func RemoveMovieByIMDB(imdbID string) (*tmdb.Movie, []string, error) {
+	tmdbID, err := resolveIMDB(imdbID, MovieType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return RemoveMovie(tmdbID)
+}
+
+// AddShowByIMDB adds show to the library by its IMDB id
+func AddShowByIMDB(imdbID string, force bool) (*tmdb.Show, error) {
+	tmdbID, err := resolveIMDB(imdbID, ShowType)
+	if err != nil {
+		return nil, err
+	}
+
+	return AddShow(strconv.Itoa(tmdbID), force, "")
+}
+
+// RemoveShowByIMDB removes show from the library by its IMDB id
+func RemoveShowByIMDB(imdbID string) (*tmdb.Show, []string, error) {
+	tmdbID, err := resolveIMDB(imdbID, ShowType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return RemoveShow(strconv.Itoa(tmdbID))
+}
+
+// resolveIMDB returns TMDB id of IMDB title, using the same cached lookup as list sync
+func resolveIMDB(imdbID string, mediaType int) (int, error) {
+	imdbID = strings.TrimSpace(imdbID)
+	if !strings.HasPrefix(imdbID, "tt") {
+		return 0, fmt.Errorf("Invalid IMDB id %q", imdbID)
+	}
+
+	ref := ExternalRef{MediaType: mediaType, Source: "imdb_id", ID: imdbID}
+	if id := BatchResolveExternalIDs([]ExternalRef{ref})[ref.Key()]; id != 0 {
+		return id, nil
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrIMDBNotResolved, imdbID)
+}
@@ -1,17 +1,21 @@
 package library
 
 import (
+	"bytes"
+	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/anacrolix/missinggo/perf"
 	"github.com/asdine/storm"
@@ -81,6 +85,27 @@ const (
 	// Deleted ...
 	Deleted
 )
+const seasonWorkers = 4
+
+// writableCheckInterval is a time, successful writability probe of library path is trusted
+const writableCheckInterval = 5 * time.Minute
+
+const (
+	// StrmURLPlugin ...
+	StrmURLPlugin = iota
+	// StrmURLHTTP ...
+	StrmURLHTTP
+)
+
+const (
+	// MovieGroupingNone keeps movie folders directly in movies library folder
+	MovieGroupingNone = iota
+	// MovieGroupingAlpha groups movie folders by first letter of the title
+	MovieGroupingAlpha
+	// MovieGroupingGenre groups movie folders by first TMDB genre
+	MovieGroupingGenre
+)
+
 const (
 	// Delete ...
 	Delete = iota
@@ -96,7 +121,9 @@ const (
 
 var (
 	removedEpisodes = make(chan *removedEpisode)
+	reconfigured    = make(chan struct{}, 1)
 	closer          = util.Event{}
+	debouncerWG     sync.WaitGroup
 
 	log = logging.MustGetLogger("library")
 
@@ -104,13 +131,28 @@ var (
 
 	initialized = false
 
-	resolveRegexp = regexp.MustCompile(`^plugin://plugin.video.elementum.*?(\d+)(\W|$)`)
+	resolveRegexp = regexp.MustCompile(`^(?:plugin://plugin.video.elementum|https?://[^/]+/library/).*?(\d+)(\W|$)`)
+
+	// emptyYearRegexp matches year placeholder with its brackets, dropped for undated titles
+	emptyYearRegexp = regexp.MustCompile(`\s*[(\[]?\{year\}[)\]]?`)
+	// repeatedSeparatorRegexp matches separators, left next to each other after year is dropped
+	repeatedSeparatorRegexp = regexp.MustCompile(`([-_.,])(\s*[-_.,])+`)
 
 	pendingShows = map[int]bool{}
+	writingShows = map[int]chan struct{}{}
+
+	writableMu     sync.Mutex
+	writableChecks = map[string]time.Time{}
+	unwritableDirs = map[string]bool{}
 
 	lock = sync.Mutex{}
 
 	ErrVideoRemoved = errors.New("Video is marked as removed")
+	ErrVideoFrozen  = errors.New("Video is frozen for updates")
+
+	ErrUnsafeRemoval = errors.New("Refusing to remove unsafe library path")
+
+	ErrNothingWritten = errors.New("No aired episodes to add yet")
 )
 
 // InitDB ...
@@ -122,15 +164,24 @@ func InitDB() {
 func Init() {
 	InitDB()
 
+	if err := MigrateSchema(); err != nil {
+		log.Errorf("Library database migration failed: %s", err)
+	}
+
 	if err := checkMoviesPath(); err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		notifyPathError(err)
 		return
 	}
 	if err := checkShowsPath(); err != nil {
-		xbmc.Notify("Elementum", err.Error(), config.AddonIcon())
+		notifyPathError(err)
 		return
 	}
 
+	caseInsensitiveFS = detectCaseInsensitiveFS(config.Get().LibraryPath)
+	if caseInsensitiveFS {
+		log.Info("Library is located on case-insensitive filesystem")
+	}
+
 	go func() {
 		// Give time to Kodi to start its JSON-RPC service
 		time.Sleep(5 * time.Second)
@@ -140,23 +191,38 @@ func Init() {
 			trakt.GetLastActivities()
 		}
 
+		runUpgradeMigration()
+
 		RefreshLocal()
 		Refresh()
 		initialized = true
 	}()
 
 	// Removed episodes debouncer
+	debouncerWG.Add(1)
 	go func() {
+		defer debouncerWG.Done()
+
 		var episodes []*removedEpisode
 
 		closing := closer.C()
 		timer := time.NewTicker(3 * time.Second)
 		defer timer.Stop()
-		defer close(removedEpisodes)
 
 		for {
 			select {
 			case <-closing:
+				// Collect episodes, that are being sent right now, and save their state,
+				// otherwise they are re-added on next start
+				for drained := false; !drained; {
+					select {
+					case episode := <-removedEpisodes:
+						episodes = append(episodes, episode)
+					default:
+						drained = true
+					}
+				}
+				persistRemovedEpisodes(episodes)
 				return
 
 			case <-timer.C:
@@ -204,7 +270,7 @@ func Init() {
 					}
 					if len(labels) > 0 {
 						label = strings.Join(labels, ", ")
-						if xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30278];;%s", label)) {
+						if confirmWithTimeout(fmt.Sprintf("LOCALIZE[30278];;%s", label)) {
 							xbmc.VideoLibraryClean()
 						}
 					}
@@ -215,7 +281,7 @@ func Init() {
 							log.Error(err)
 						}
 					}
-					if xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30278];;%s", label)) {
+					if confirmWithTimeout(fmt.Sprintf("LOCALIZE[30278];;%s", label)) {
 						xbmc.VideoLibraryClean()
 					}
 				}
@@ -255,7 +321,7 @@ func Init() {
 	go func() {
 		time.Sleep(30 * time.Second)
 		if !tmdb.WarmingUp.IsSet() {
-			xbmc.Notify("Elementum", "LOCALIZE[30147]", config.AddonIcon())
+			notify("LOCALIZE[30147]")
 		}
 	}()
 
@@ -273,22 +339,44 @@ func Init() {
 	tmdb.WarmingUp.Set()
 	took := time.Since(started)
 	if took.Seconds() > 30 {
-		xbmc.Notify("Elementum", "LOCALIZE[30148]", config.AddonIcon())
+		notify("LOCALIZE[30148]")
 	}
 	log.Noticef("Caches warmed up in %s", took)
 
-	updateFrequency := util.Max(1, config.Get().UpdateFrequency)
-	traktFrequency := util.Max(1, config.Get().TraktSyncFrequencyMin)
-
-	updateTicker := time.NewTicker(time.Duration(updateFrequency) * time.Hour)
-	traktSyncTicker := time.NewTicker(time.Duration(traktFrequency) * time.Minute)
+	updateTicker := time.NewTicker(updateInterval())
+	traktSyncTicker := time.NewTicker(traktSyncInterval())
 	markedForRemovalTicker := time.NewTicker(30 * time.Second)
 	watcherTicker := time.NewTicker(1 * time.Second)
+	trashTicker := time.NewTicker(1 * time.Hour)
 
-	defer updateTicker.Stop()
-	defer traktSyncTicker.Stop()
+	// Update tickers are re-created on interval change, so deferred call should see the current ones
+	defer func() {
+		updateTicker.Stop()
+		traktSyncTicker.Stop()
+	}()
 	defer markedForRemovalTicker.Stop()
 	defer watcherTicker.Stop()
+	defer trashTicker.Stop()
+
+	// Scrubber is optional, nil channel is never selected
+	var scrubTicker *time.Ticker
+	var scrubC <-chan time.Time
+	resetScrubTicker := func() {
+		if scrubTicker != nil {
+			scrubTicker.Stop()
+			scrubTicker, scrubC = nil, nil
+		}
+		if hours := config.Get().ScrubIntervalHours; hours > 0 {
+			scrubTicker = time.NewTicker(time.Duration(hours) * time.Hour)
+			scrubC = scrubTicker.C
+		}
+	}
+	resetScrubTicker()
+	defer func() {
+		if scrubTicker != nil {
+			scrubTicker.Stop()
+		}
+	}()
 
 	closing := closer.C()
 
@@ -302,9 +390,9 @@ func Init() {
 				go RefreshKodi()
 			} else if l.Pending.IsTrakt {
 				go RefreshTrakt()
-			} else if l.Pending.IsMovies {
+			} else if l.Pending.IsMovies && !IsMediaTypePaused(MovieType) {
 				go RefreshMovies()
-			} else if l.Pending.IsShows {
+			} else if l.Pending.IsShows && !IsMediaTypePaused(ShowType) {
 				go RefreshShows()
 			} else if l.Pending.IsEpisodes {
 				go RefreshEpisodes()
@@ -312,7 +400,7 @@ func Init() {
 				go Refresh()
 			}
 		case <-updateTicker.C:
-			if config.Get().UpdateFrequency > 0 && config.Get().LibraryEnabled && config.Get().LibrarySyncEnabled && (config.Get().LibrarySyncPlaybackEnabled || !xbmc.PlayerIsPlaying()) {
+			if config.Get().UpdateFrequency > 0 && config.Get().LibraryEnabled && config.Get().LibrarySyncEnabled && (config.Get().LibrarySyncPlaybackEnabled || !xbmc.PlayerIsPlaying()) && !IsMediaTypePaused(ShowType) {
 				go func() {
 					if err := updateLibraryShows(); err != nil {
 						log.Warning(err)
@@ -323,6 +411,22 @@ func Init() {
 			}
 		case <-traktSyncTicker.C:
 			PlanTraktUpdate()
+		case <-reconfigured:
+			updateTicker.Stop()
+			updateTicker = time.NewTicker(updateInterval())
+			traktSyncTicker.Stop()
+			traktSyncTicker = time.NewTicker(traktSyncInterval())
+			resetScrubTicker()
+			log.Infof("Library update intervals reconfigured: updates every %s, Trakt sync every %s", updateInterval(), traktSyncInterval())
+		case <-trashTicker.C:
+			purgeTrash()
+			if _, err := PurgeDeletedItems(); err != nil {
+				log.Warningf("Could not purge deleted items: %s", err)
+			}
+		case <-scrubC:
+			if config.Get().LibraryEnabled && (config.Get().LibrarySyncPlaybackEnabled || !xbmc.PlayerIsPlaying()) {
+				go runScrub()
+			}
 		case <-markedForRemovalTicker.C:
 			var items []database.BTItem
 			database.GetStormDB().Select(q.Eq("State", database.StateDeleted)).Find(&items)
@@ -353,6 +457,25 @@ func Init() {
 	}
 }
 
+// Reconfigure applies changed configuration of library update intervals without a restart
+func Reconfigure() {
+	select {
+	case reconfigured <- struct{}{}:
+	default:
+		// Already signaled, intervals are read from config when signal is handled
+	}
+}
+
+// updateInterval returns interval of scheduled library updates
+func updateInterval() time.Duration {
+	return time.Duration(util.Max(1, config.Get().UpdateFrequency)) * time.Hour
+}
+
+// traktSyncInterval returns interval of scheduled Trakt sync
+func traktSyncInterval() time.Duration {
+	return time.Duration(util.Max(1, config.Get().TraktSyncFrequencyMin)) * time.Minute
+}
+
 // MoviesLibraryPath contains calculated path for saving Movies strm files
 func MoviesLibraryPath() string {
 	return filepath.Join(config.Get().LibraryPath, "Movies")
@@ -367,32 +490,50 @@ func ShowsLibraryPath() string {
 // Library updates
 //
 func updateLibraryShows() error {
-	if !config.Get().LibraryEnabled || !config.Get().LibrarySyncEnabled || (!config.Get().LibrarySyncPlaybackEnabled && xbmc.PlayerIsPlaying()) {
+	return UpdateLibraryShowsContext(context.Background())
+}
+
+// UpdateLibraryShowsContext writes new episodes of library shows, stops between shows when ctx is cancelled
+func UpdateLibraryShowsContext(ctx context.Context) error {
+	if !config.Get().LibraryEnabled || !config.Get().LibrarySyncEnabled || (!config.Get().LibrarySyncPlaybackEnabled && xbmc.PlayerIsPlaying()) || IsMediaTypePaused(ShowType) {
 		return nil
 	}
 
 	if err := checkShowsPath(); err != nil {
 		return err
 	}
+	defer beginManifestBatch()()
+	defer beginFolderIndex()()
 
 	begin := time.Now()
 
-	var lis []database.LibraryItem
-	if err := database.GetStormDB().Select(q.Eq("MediaType", ShowType), q.Eq("State", StateActive)).Find(&lis); err != nil && err != storm.ErrNotFound {
+	lis, err := ListLibraryItems(ShowType, StateActive)
+	if err != nil {
 		log.Infof("Could not get list of library items: %s", err)
 	}
 
-	for _, i := range lis {
+	newEpisodes := []NewEpisode{}
+	for n, i := range lis {
 		if closer.IsSet() {
 			return nil
+		} else if err := ctx.Err(); err != nil {
+			return err
 		}
-		if i.ID == 0 || i.ShowID == 0 {
+		reportProgress(Progress{Operation: ProgressUpdateShows, MediaType: ShowType, Current: n + 1, Total: len(lis)})
+		if i.ID == 0 || i.ShowID == 0 || i.Frozen {
 			continue
 		}
 
-		if _, err := writeShowStrm(i.ShowID, false, false); err != nil {
+		_, _, added, err := writeShowStrm(i.ShowID, false, false)
+		if err != nil {
 			log.Errorf("Error updating show: %s", err)
 		}
+		newEpisodes = append(newEpisodes, added...)
+	}
+	setLastNewEpisodes(newEpisodes)
+	if len(newEpisodes) > 0 {
+		log.Infof("Library update found %d new episodes", len(newEpisodes))
+		notifyNewEpisodes(newEpisodes)
 	}
 
 	log.Infof("Library updated in %s", time.Since(begin))
@@ -409,7 +550,7 @@ func checkLibraryPath() error {
 		log.Warningf("Library path is not initialized")
 		return errors.New("LOCALIZE[30220]")
 	}
-	if fileInfo, err := os.Stat(libraryPath); err != nil {
+	if fileInfo, err := libFS.Stat(libraryPath); err != nil {
 		if fileInfo == nil {
 			log.Warningf("Library path is invalid")
 			return errors.New("Invalid library path")
@@ -422,7 +563,67 @@ func checkLibraryPath() error {
 		log.Warningf("Error getting Library path: %v", err)
 		return err
 	}
-	return nil
+	return checkWritable(libraryPath)
+}
+
+// writableError reports library folder, that could not be written,
+// user is already notified about it by the probe
+type writableError struct {
+	dir string
+}
+
+func (e *writableError) Error() string {
+	return fmt.Sprintf("LOCALIZE[30671];;%s", e.dir)
+}
+
+// notifyPathError notifies user about failed path check, unless the probe already did
+func notifyPathError(err error) {
+	var werr *writableError
+	if !errors.As(err, &werr) {
+		notify(err.Error())
+	}
+}
+
+// checkWritable probes directory with a temporary file, successful probe is remembered for a while,
+// so frequent path checks do not write to disk each time. User is notified once, when directory
+// becomes not writable, and again only after it was writable in between
+func checkWritable(dir string) error {
+	writableMu.Lock()
+	if checked, ok := writableChecks[dir]; ok && time.Since(checked) < writableCheckInterval {
+		writableMu.Unlock()
+		return nil
+	}
+
+	err := probeWritable(dir)
+	notified := unwritableDirs[dir]
+	if err != nil {
+		delete(writableChecks, dir)
+		unwritableDirs[dir] = true
+	} else {
+		delete(unwritableDirs, dir)
+		writableChecks[dir] = time.Now()
+	}
+	writableMu.Unlock()
+
+	if err == nil {
+		return nil
+	}
+
+	log.Warningf("Library path %s is not writable: %s", dir, err)
+	werr := &writableError{dir: dir}
+	if !notified {
+		notify(werr.Error())
+	}
+	return werr
+}
+
+// probeWritable writes and removes a temporary file in the directory
+func probeWritable(dir string) error {
+	probe := filepath.Join(dir, fmt.Sprintf(".elementum_write_test_%d", time.Now().UnixNano()))
+	if err := libFS.WriteFile(probe, []byte{}, 0644); err != nil {
+		return err
+	}
+	return libFS.Remove(probe)
 }
 
 func checkMoviesPath() error {
@@ -431,12 +632,16 @@ func checkMoviesPath() error {
 	}
 
 	moviesLibraryPath := MoviesLibraryPath()
-	if _, err := os.Stat(moviesLibraryPath); os.IsNotExist(err) {
-		if err := os.Mkdir(moviesLibraryPath, 0755); err != nil {
+	if _, err := libFS.Stat(moviesLibraryPath); os.IsNotExist(err) {
+		if err := libFS.Mkdir(moviesLibraryPath, 0755); err != nil {
 			log.Error(err)
 			return err
 		}
 	}
+	if err := checkWritable(moviesLibraryPath); err != nil {
+		return err
+	}
+	ensureMediaFolders(MoviesLibraryPaths())
 	return nil
 }
 
@@ -446,12 +651,16 @@ func checkShowsPath() error {
 	}
 
 	showsLibraryPath := ShowsLibraryPath()
-	if _, err := os.Stat(showsLibraryPath); os.IsNotExist(err) {
-		if err := os.Mkdir(showsLibraryPath, 0755); err != nil {
+	if _, err := libFS.Stat(showsLibraryPath); os.IsNotExist(err) {
+		if err := libFS.Mkdir(showsLibraryPath, 0755); err != nil {
 			log.Error(err)
 			return err
 		}
 	}
+	if err := checkWritable(showsLibraryPath); err != nil {
+		return err
+	}
+	ensureMediaFolders(ShowsLibraryPaths())
 	return nil
 }
 
@@ -459,78 +668,274 @@ func checkShowsPath() error {
 // Writers
 //
 
-func writeMovieStrm(tmdbID string, force bool) (*tmdb.Movie, error) {
+func writeMovieStrm(tmdbID string, force bool) (*tmdb.Movie, []string, error) {
 	// We should not write strm files for movies that are marked as deleted
 	ID, _ := strconv.Atoi(tmdbID)
-	if wasRemoved(ID, MovieType) && !force {
-		return nil, ErrVideoRemoved
+	if WasRemoved(ID, MovieType) && !force {
+		return nil, nil, ErrVideoRemoved
+	} else if isFrozen(ID, MovieType) && !force {
+		return nil, nil, ErrVideoFrozen
 	}
 
 	movie := tmdb.GetMovieByID(tmdbID, config.Get().StrmLanguage)
 	if movie == nil {
-		return nil, errors.New("Can't find the movie")
+		return nil, nil, errors.New("Can't find the movie")
 	}
+	// Items, stored before titles were tracked, get them on the next write
+	setItemTitles(MovieType, map[int]ItemTitle{movie.ID: movieItemTitle(movie)})
+
+	movieName := movieTitle(movie)
+	movieStrm := withFolderID(movieStrmName(config.Get().MovieStrmTemplate, movie, movieName, getMovieYear(movie)), movie.ID)
+	isFlat := config.Get().MovieLibraryFlat
 
-	movieName := movie.OriginalTitle
-	if config.Get().StrmLanguage != config.Get().Language && movie.Title != "" {
-		movieName = movie.Title
+	baseRoot := movieBaseRoot(movie)
+	movieRoot := baseRoot
+	if group := movieGroupFolder(movie, movieName); group != "" && !isFlat {
+		movieRoot = filepath.Join(movieRoot, group)
+		if _, err := libFS.Stat(movieRoot); os.IsNotExist(err) {
+			if err := libFS.Mkdir(movieRoot, 0755); err != nil {
+				log.Error(err)
+				return movie, nil, err
+			}
+		}
 	}
-	movieStrm := util.ToFileName(fmt.Sprintf("%s (%s)", movieName, strings.Split(movie.ReleaseDate, "-")[0]))
-	moviePath := filepath.Join(MoviesLibraryPath(), movieStrm)
 
-	if _, err := os.Stat(moviePath); os.IsNotExist(err) {
-		if err := os.Mkdir(moviePath, 0755); err != nil {
+	movieStrm = fitNameLogged(movieStrm, movieNameRoom(movieRoot, isFlat))
+	movieStrm = disambiguateFolder(movieRoot, movieStrm, MovieType, movie.ID)
+	moviePath := filepath.Join(movieRoot, movieStrm)
+
+	if isFlat {
+		// Movie files are written directly into movies library folder
+		moviePath = baseRoot
+	} else if _, err := libFS.Stat(moviePath); os.IsNotExist(err) {
+		if err := libFS.Mkdir(moviePath, 0755); err != nil {
 			log.Error(err)
-			return movie, err
+			return movie, nil, err
 		}
-	} else if force {
-		os.Chtimes(moviePath, time.Now().Local(), time.Now().Local())
 	}
 
 	movieStrmPath := filepath.Join(moviePath, fmt.Sprintf("%s.strm", movieStrm))
+	if force {
+		preserveMovieState(movie.ID)
+	}
+	written := []string{}
 	if config.Get().LibraryNFOMovies {
-		writeMovieNFO(movie, filepath.Join(moviePath, fmt.Sprintf("%s.nfo", movieStrm)))
+		movieNFOPath := filepath.Join(moviePath, fmt.Sprintf("%s.nfo", movieStrm))
+		if err := writeMovieNFO(movie, movieNFOPath); err == nil {
+			written = append(written, movieNFOPath)
+		}
+	}
+	if config.Get().LibraryDownloadArtwork {
+		artwork := movieArtwork(movie)
+		if isFlat {
+			artwork = prefixArtwork(movieStrm, artwork)
+		}
+		written = append(written, writeArtwork(moviePath, artwork)...)
+	}
+
+	playLink := moviePlayLink(movie.ID)
+	parts := config.Get().MovieStrmParts
+	strmPaths := []string{movieStrmPath}
+	if parts > 1 {
+		strmPaths = []string{}
+		for part := 1; part <= parts; part++ {
+			strmPaths = append(strmPaths, moviePartStrmPath(moviePath, movieStrm, part))
+		}
 	}
 
-	playLink := URLForXBMC("/library/movie/play/%s", tmdbID)
-	if _, err := os.Stat(movieStrmPath); !force && err == nil {
+	if _, err := libFS.Stat(strmPaths[0]); !force && err == nil {
 		// log.Debugf("Movie strm file already exists at %s", movieStrmPath)
 		// return movie, fmt.Errorf("LOCALIZE[30287];;%s", movie.Title)
-		return movie, nil
+		return movie, written, nil
+	}
+	changed := false
+	for i, p := range strmPaths {
+		link := playLink
+		if parts > 1 {
+			link = addLinkQuery(playLink, "part", strconv.Itoa(i+1))
+		}
+		if isUnchangedFile(p, []byte(link)) {
+			continue
+		}
+		if err := writeFileRetry(p, []byte(link), 0644); err != nil {
+			log.Errorf("Could not write strm file: %s", err)
+			return movie, written, err
+		}
+		written = append(written, p)
+		changed = true
+	}
+	if force && !changed {
+		// Flat movie has no own folder, so its strm file is touched instead
+		if isFlat {
+			touchPath(strmPaths[0])
+		} else {
+			touchPath(moviePath)
+		}
+	}
+
+	// Switching between single and multi-part files should not leave both of them
+	if parts > 1 {
+		if _, err := libFS.Stat(movieStrmPath); err == nil {
+			libFS.Remove(movieStrmPath)
+		}
+	} else {
+		for _, p := range existingMoviePartStrm(moviePath, movieStrm) {
+			libFS.Remove(p)
+		}
 	}
-	if err := ioutil.WriteFile(movieStrmPath, []byte(playLink), 0644); err != nil {
-		log.Errorf("Could not write strm file: %s", err)
-		return movie, err
+
+	manifestPath := moviePath
+	if isFlat {
+		manifestPath = movieStrmPath
 	}
+	updateManifest(baseRoot, &ManifestItem{
+		TMDBID:    movie.ID,
+		MediaType: MovieType,
+		Title:     movieName,
+		Path:      manifestPath,
+		WrittenAt: time.Now(),
+	})
 
-	return movie, nil
+	return movie, written, nil
 }
 
 func writeMovieNFO(m *tmdb.Movie, p string) error {
+	out, err := renderNFO(movieNFOTemplateName, defaultMovieNFOTemplate, m, movieNFOExtra(m))
+	if err != nil {
+		log.Errorf("Could not render NFO file: %s", err)
+		return err
+	}
+
+	if isUnchangedFile(p, []byte(out)) {
+		return nil
+	}
+	if err := libFS.WriteFile(p, []byte(out), 0644); err != nil {
+		log.Errorf("Could not write NFO file: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// movieNFOExtra returns movie metadata elements for movie NFO
+func movieNFOExtra(m *tmdb.Movie) string {
+	extra := nfoTag("title", m.Title)
+	if m.OriginalTitle != m.Title {
+		extra += nfoTag("originaltitle", m.OriginalTitle)
+	}
+	extra += nfoTag("year", getMovieYear(m))
+	extra += nfoTag("plot", m.Overview)
+	if m.Runtime > 0 {
+		extra += nfoTag("runtime", strconv.Itoa(m.Runtime))
+	}
+	for _, g := range m.Genres {
+		if g != nil {
+			extra += nfoTag("genre", g.Name)
+		}
+	}
+	if m.VoteCount > 0 {
+		extra += nfoTag("rating", fmt.Sprintf("%.1f", m.VoteAverage))
+	}
+	extra += nfoTag("premiered", m.ReleaseDate)
+	extra += nfoTag("tagline", m.TagLine)
+	extra += nfoTag("trailer", getMovieTrailer(m))
+	extra += creditsNFO(m.Credits)
+	extra += artworkNFO(m.PosterPath, m.BackdropPath)
+	extra += addedByTag(m.ID)
+	if c := getMovieCollection(m); c != nil {
+		extra += fmt.Sprintf(`
+	<set>
+		<name>%s</name>
+		<overview>%s</overview>
+	</set>`, escapeXML(c.Name), escapeXML(c.Overview))
+	}
+
+	return extra
+}
+
+// getMovieCollection returns collection (movie set) the movie belongs to,
+// collection details are cached by tmdb, so members of the same set share one lookup.
+func getMovieCollection(m *tmdb.Movie) *tmdb.Collection {
+	if m.BelongsToCollection == nil || m.BelongsToCollection.ID == 0 {
+		return nil
+	}
+
+	if c := tmdb.GetCollection(m.BelongsToCollection.ID, config.Get().StrmLanguage); c != nil && c.Name != "" {
+		return c
+	}
+	return m.BelongsToCollection
+}
+
+// episodeNFOName returns path of NFO file, placed next to episode strm file
+func episodeNFOName(strmPath string) string {
+	return strings.TrimSuffix(strmPath, ".strm") + ".nfo"
+}
+
+// removeEpisodeFile removes episode strm file together with its NFO file
+func removeEpisodeFile(strmPath string) error {
+	if err := libFS.Remove(strmPath); err != nil {
+		return err
+	}
+
+	nfoPath := episodeNFOName(strmPath)
+	if _, err := libFS.Stat(nfoPath); err == nil {
+		libFS.Remove(nfoPath)
+	}
+	return nil
+}
+
+// renameEpisodeFile renames episode strm file together with its NFO file
+func renameEpisodeFile(oldStrmPath, newStrmPath string) error {
+	if err := libFS.Rename(oldStrmPath, newStrmPath); err != nil {
+		return err
+	}
+
+	oldNFOPath := episodeNFOName(oldStrmPath)
+	if _, err := libFS.Stat(oldNFOPath); err == nil {
+		if err := libFS.Rename(oldNFOPath, episodeNFOName(newStrmPath)); err != nil {
+			log.Warningf("Could not rename %s: %s", oldNFOPath, err)
+		}
+	}
+	return nil
+}
+
+func writeEpisodeNFO(s *tmdb.Show, season int, e *tmdb.Episode, p string) error {
 	out := `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
-<movie>
-	<uniqueid type="unknown" default="false">%v</uniqueid>
-	<uniqueid type="elementum" default="false">%v</uniqueid>
-	<uniqueid type="tmdb" default="true">%v</uniqueid>
-	<uniqueid type="imdb" default="false">%v</uniqueid>
-	<uniqueid type="tvdb" default="false">%v</uniqueid>
-</movie>
-https://www.themoviedb.org/movie/%v
+<episodedetails>
+	<uniqueid type="tmdb" default="true">%v</uniqueid>%s%s
+	<season>%d</season>
+	<episode>%d</episode>
+</episodedetails>
 `
+	ids := ""
+	if e.ExternalIDs != nil {
+		if e.ExternalIDs.IMDBId != "" {
+			ids += fmt.Sprintf(`
+	<uniqueid type="imdb" default="false">%s</uniqueid>`, escapeXML(e.ExternalIDs.IMDBId))
+		}
+		if tvdbID := util.StrInterfaceToInt(e.ExternalIDs.TVDBID); tvdbID != 0 {
+			ids += fmt.Sprintf(`
+	<uniqueid type="tvdb" default="false">%d</uniqueid>`, tvdbID)
+		}
+	}
+
+	extra := nfoTag("title", e.Name)
+	extra += nfoTag("showtitle", s.Name)
+	extra += nfoTag("plot", e.Overview)
+	extra += nfoTag("aired", e.AirDate)
+
 	out = fmt.Sprintf(out,
-		m.ID,
-		m.ID,
-		m.ID,
-		m.ExternalIDs.IMDBId,
-		m.ExternalIDs.TVDBID,
-		m.ID,
+		e.ID,
+		ids,
+		extra,
+		season,
+		e.EpisodeNumber,
 	)
 
-	if m.ExternalIDs.IMDBId != "" {
-		out += fmt.Sprintf("https://www.imdb.com/title/%s/\n", m.ExternalIDs.IMDBId)
+	if isUnchangedFile(p, []byte(out)) {
+		return nil
 	}
-
-	if err := ioutil.WriteFile(p, []byte(out), 0644); err != nil {
+	if err := libFS.WriteFile(p, []byte(out), 0644); err != nil {
 		log.Errorf("Could not write NFO file: %s", err)
 		return err
 	}
@@ -538,40 +943,243 @@ https://www.themoviedb.org/movie/%v
 	return nil
 }
 
-func writeShowStrm(showID int, adding, force bool) (*tmdb.Show, error) {
+// showNFOExtra returns show metadata elements for tvshow.nfo
+func showNFOExtra(s *tmdb.Show, seasons int) string {
+	extra := nfoTag("title", s.Name)
+	if s.OriginalName != s.Name {
+		extra += nfoTag("originaltitle", s.OriginalName)
+	}
+	extra += nfoTag("plot", s.Overview)
+	if year := strings.Split(s.FirstAirDate, "-")[0]; len(year) == 4 {
+		extra += nfoTag("year", year)
+		extra += nfoTag("premiered", s.FirstAirDate)
+	}
+	for _, n := range s.Networks {
+		if n != nil {
+			extra += nfoTag("studio", n.Name)
+		}
+	}
+	for _, g := range s.Genres {
+		if g != nil {
+			extra += nfoTag("genre", g.Name)
+		}
+	}
+	if s.VoteCount > 0 {
+		extra += nfoTag("rating", fmt.Sprintf("%.1f", s.VoteAverage))
+	}
+	if seasons == 0 {
+		seasons = s.NumberOfSeasons
+	}
+	if seasons > 0 {
+		extra += nfoTag("season", strconv.Itoa(seasons))
+	}
+	if config.Get().LibraryNFOSeasons {
+		extra += namedSeasonsNFO(s.Seasons)
+	}
+	extra += creditsNFO(s.Credits)
+	extra += artworkNFO(s.PosterPath, s.BackdropPath)
+	for _, season := range s.Seasons {
+		if season != nil && season.Poster != "" {
+			extra += fmt.Sprintf(`
+	<thumb aspect="poster" type="season" season="%d">%s</thumb>`, season.Season, escapeXML(tmdb.ImageURL(season.Poster, "original")))
+		}
+	}
+
+	return extra + addedByTag(s.ID)
+}
+
+// namedSeasonsNFO returns season names for tvshow.nfo, flat show folders have no
+// season folder for season.nfo
+func namedSeasonsNFO(seasons tmdb.SeasonList) string {
+	extra := ""
+	for _, season := range seasons {
+		if season == nil || season.Name == "" || season.EpisodeCount == 0 {
+			continue
+		}
+		extra += fmt.Sprintf(`
+	<namedseason number="%d">%s</namedseason>`, season.Season, escapeXML(season.Name))
+	}
+
+	return extra
+}
+
+// getMovieTrailer returns Kodi play link for the first movie trailer,
+// English trailers are fetched as a fallback only in full NFO mode.
+func getMovieTrailer(m *tmdb.Movie) string {
+	if m.Trailers != nil {
+		for _, trailer := range m.Trailers.Youtube {
+			if trailer != nil && trailer.Source != "" {
+				return util.TrailerURL(trailer.Source)
+			}
+		}
+	}
+
+	if !config.Get().LibraryNFOFull || config.Get().StrmLanguage == "en" {
+		return ""
+	}
+
+	if enMovie := tmdb.GetMovie(m.ID, "en"); enMovie != nil && enMovie.Trailers != nil {
+		for _, trailer := range enMovie.Trailers.Youtube {
+			if trailer != nil && trailer.Source != "" {
+				return util.TrailerURL(trailer.Source)
+			}
+		}
+	}
+
+	return ""
+}
+
+// creditsNFO returns director, writer and actor elements for top cast members,
+// credits are written only if cast count is configured
+func creditsNFO(c *tmdb.Credits) string {
+	limit := config.Get().LibraryNFOCastCount
+	if c == nil || limit <= 0 {
+		return ""
+	}
+
+	extra := ""
+	for _, crew := range c.Crew {
+		if crew == nil {
+			continue
+		}
+		if crew.Job == "Director" {
+			extra += nfoTag("director", crew.Name)
+		} else if crew.Department == "Writing" {
+			extra += nfoTag("credits", crew.Name)
+		}
+	}
+
+	for i, cast := range c.Cast {
+		if i >= limit {
+			break
+		} else if cast == nil || cast.Name == "" {
+			continue
+		}
+
+		extra += fmt.Sprintf(`
+	<actor>
+		<name>%s</name>
+		<role>%s</role>
+		<order>%d</order>%s
+	</actor>`, escapeXML(cast.Name), escapeXML(cast.Character), i, thumbTag(cast.ProfilePath))
+	}
+
+	return extra
+}
+
+// artworkNFO returns poster and fanart elements with TMDB image links, missing images are omitted
+func artworkNFO(poster, backdrop string) string {
+	extra := ""
+	if poster != "" {
+		extra += fmt.Sprintf(`
+	<thumb aspect="poster">%s</thumb>`, escapeXML(tmdb.ImageURL(poster, "original")))
+	}
+	if backdrop != "" {
+		extra += fmt.Sprintf(`
+	<fanart>
+		<thumb>%s</thumb>
+	</fanart>`, escapeXML(tmdb.ImageURL(backdrop, "original")))
+	}
+
+	return extra
+}
+
+// thumbTag returns nested thumb element with TMDB image link, empty path is omitted
+func thumbTag(path string) string {
+	if path == "" {
+		return ""
+	}
+	return "\n\t\t<thumb>" + escapeXML(tmdb.ImageURL(path, "original")) + "</thumb>"
+}
+
+// nfoTag returns NFO element with escaped value, empty values are omitted
+func nfoTag(name, value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("\n\t<%s>%s</%s>", name, escapeXML(value), name)
+}
+
+func escapeXML(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func writeShowStrm(showID int, adding, force bool) (*tmdb.Show, []string, []NewEpisode, error) {
 	// We should not write strm files for shows that are marked as deleted
-	if wasRemoved(showID, ShowType) && !force {
-		return nil, ErrVideoRemoved
+	if WasRemoved(showID, ShowType) && !force {
+		return nil, nil, nil, ErrVideoRemoved
+	} else if isFrozen(showID, ShowType) && !force {
+		return nil, nil, nil, ErrVideoFrozen
 	}
 
 	defer perf.ScopeTimer()()
+	defer acquireShowWrite(showID)()
 
 	show := tmdb.GetShow(showID, config.Get().StrmLanguage)
 	if show == nil {
-		return nil, fmt.Errorf("Unable to get show (%d)", showID)
+		return nil, nil, nil, fmt.Errorf("Unable to get show (%d)", showID)
 	}
+	// Items, stored before titles were tracked, get them on the next write
+	setItemTitles(ShowType, map[int]ItemTitle{show.ID: showItemTitle(show)})
 
 	showPath, showStrm := getShowPath(show)
+	if force {
+		preserveShowState(show.ID)
+	}
 
-	if _, err := os.Stat(showPath); os.IsNotExist(err) {
-		if err := os.Mkdir(showPath, 0755); err != nil {
+	if _, err := libFS.Stat(showPath); os.IsNotExist(err) {
+		if err := libFS.Mkdir(showPath, 0755); err != nil {
 			log.Error(err)
-			return show, err
+			return show, nil, nil, err
 		}
-	} else if force {
-		os.Chtimes(showPath, time.Now().Local(), time.Now().Local())
 	}
 
-	if config.Get().LibraryNFOShows {
-		writeShowNFO(show, filepath.Join(showPath, "tvshow.nfo"))
+	written := []string{}
+	added := []NewEpisode{}
+	if config.Get().LibraryDownloadArtwork {
+		written = append(written, writeArtwork(showPath, showArtwork(show))...)
 	}
 
 	addSpecials := config.Get().AddSpecials
+	existingStrm := episodeStrmFiles(showPath, showStrm)
+	changed := false
+
+	// Anime episodes are named by absolute number, while play links keep season and episode
+	var absolute func(season, episode int) int
+	if isAnimeAbsolute(show) {
+		absolute = showAbsoluteNumbers(show)
+	}
+	absolutes := absoluteEpisodes(show, absolute)
+	seasonsCount := 0
+
+	// Keep only recent seasons, specials are controlled by AddSpecials only
+	minSeason := 0
+	if keep := config.Get().KeepLastNSeasons; keep > 0 {
+		minSeason = firstKeptSeason(show, keep)
+		removeSeasonsBefore(show, existingStrm, minSeason, absolutes)
+	}
+
+	// Per-show season range narrows written seasons further
+	maxSeason := 0
+	if r, ok := config.Get().ShowSeasonRanges[show.ID]; ok {
+		minSeason = util.Max(minSeason, r[0])
+		maxSeason = r[1]
+		if config.Get().LibraryPruneStaleEpisodes {
+			removeSeasonsOutside(show, existingStrm, minSeason, maxSeason, absolutes)
+		}
+	}
 
+	seasons := []*tmdb.Season{}
 	for _, season := range show.Seasons {
 		if season.EpisodeCount == 0 {
 			continue
 		}
+		if season.Season > 0 && (season.Season < minSeason || (maxSeason > 0 && season.Season > maxSeason)) {
+			continue
+		}
 		if config.Get().ShowUnairedSeasons == false {
 			if _, isExpired := util.AirDateWithExpireCheck(show.FirstAirDate, config.Get().ShowEpisodesOnReleaseDay); isExpired {
 				continue
@@ -581,17 +1189,26 @@ func writeShowStrm(showID int, adding, force bool) (*tmdb.Show, error) {
 			continue
 		}
 
-		seasonTMDB := tmdb.GetSeason(showID, season.Season, config.Get().Language, len(show.Seasons))
+		seasons = append(seasons, season)
+	}
+
+	var reAddIDs []int
+	for i, seasonTMDB := range fetchShowSeasons(show, seasons) {
+		season := seasons[i]
 		if seasonTMDB == nil {
 			continue
 		}
 		episodes := seasonTMDB.Episodes
+		if season.Season > 0 && len(episodes) > 0 {
+			seasonsCount++
+		}
 
-		var reAddIDs []int
+		known := map[string]bool{}
 		for _, episode := range episodes {
 			if episode == nil {
 				continue
 			}
+			known[episodeCode(season.Season, episode.EpisodeNumber)] = true
 
 			if config.Get().ShowUnairedEpisodes == false {
 				if episode.AirDate == "" {
@@ -610,57 +1227,388 @@ func writeShowStrm(showID int, adding, force bool) (*tmdb.Show, error) {
 				continue
 			}
 
-			episodeStrmPath := filepath.Join(showPath, fmt.Sprintf("%s S%02dE%02d.strm", showStrm, season.Season, episode.EpisodeNumber))
-			playLink := URLForXBMC("/library/show/play/%d/%d/%d", showID, season.Season, episode.EpisodeNumber)
-			if _, err := os.Stat(episodeStrmPath); !force && err == nil {
+			seasonPath := episodeDir(showPath, season.Season)
+			episodeStrmPath := filepath.Join(seasonPath, episodeStrmName(showStrm, season.Season, episode.EpisodeNumber, episode.Name))
+			if isPathTooLong(episodeStrmPath) {
+				// Episode title is dropped first, as it is the only optional part of file name
+				log.Warningf("Episode file %s is too long for library path, writing it without episode title", episodeStrmPath)
+				episodeStrmPath = filepath.Join(seasonPath, episodeStrmName(showStrm, season.Season, episode.EpisodeNumber, ""))
+			}
+			playLink := episodePlayLink(showID, season.Season, episode.EpisodeNumber)
+			existing := existingStrm[episodeCode(season.Season, episode.EpisodeNumber)]
+			if absolute != nil {
+				if an := absolute(season.Season, episode.EpisodeNumber); an > 0 {
+					episodeStrmPath = filepath.Join(seasonPath, animeStrmName(showStrm, an))
+					existing = append(append([]string{}, existing...), existingStrm[absoluteCode(an)]...)
+				}
+			}
+			if !force && len(existing) > 0 {
 				continue
 			}
 
-			if err := ioutil.WriteFile(episodeStrmPath, []byte(playLink), 0644); err != nil {
-				log.Error(err)
-				return show, err
+			// Forced refresh keeps identical files, so their modification time is not churned
+			if len(existing) == 0 || !isUnchangedFile(episodeStrmPath, []byte(playLink)) {
+				if _, err := ensureEpisodeDir(showPath, season.Season); err != nil {
+					log.Error(err)
+					return show, written, added, err
+				}
+				if err := writeFileRetry(episodeStrmPath, []byte(playLink), 0644); err != nil {
+					log.Error(err)
+					return show, written, added, err
+				}
+				written = append(written, episodeStrmPath)
+				changed = true
 			}
-		}
-		if len(reAddIDs) > 0 {
-			if err := updateBatchDBItem(reAddIDs, StateActive, EpisodeType, showID); err != nil {
-				log.Error(err)
+			if len(existing) == 0 {
+				added = append(added, NewEpisode{
+					TMDBID:   episode.ID,
+					ShowID:   showID,
+					ShowName: show.Name,
+					Season:   season.Season,
+					Episode:  episode.EpisodeNumber,
+					Title:    episode.Name,
+				})
 			}
-		}
-	}
 
-	return show, nil
-}
+			if config.Get().LibraryNFOEpisodes {
+				episodeNFOPath := episodeNFOName(episodeStrmPath)
+				if err := writeEpisodeNFO(show, season.Season, episode, episodeNFOPath); err == nil {
+					written = append(written, episodeNFOPath)
+				}
+			}
 
-func writeShowNFO(s *tmdb.Show, p string) error {
-	out := `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
-<tvshow>
-	<uniqueid type="unknown" default="false">%v</uniqueid>
-	<uniqueid type="elementum" default="false">%v</uniqueid>
-	<uniqueid type="tmdb" default="true">%v</uniqueid>
-	<uniqueid type="imdb" default="false">%v</uniqueid>
-	<uniqueid type="tvdb" default="false">%v</uniqueid>
-</tvshow>
-https://www.themoviedb.org/tv/%v
-`
-	out = fmt.Sprintf(out,
-		s.ID,
-		s.ID,
-		s.ID,
-		s.ExternalIDs.IMDBId,
-		s.ExternalIDs.TVDBID,
-		s.ID,
-	)
+			// File name could change after toggling episode titles or TMDB title edit
+			for _, p := range existing {
+				if p != episodeStrmPath {
+					removeEpisodeFile(p)
+				}
+			}
+		}
+
+		if config.Get().LibraryNFOSeasons && useSeasonFolders() {
+			if dir := episodeDir(showPath, season.Season); dir != showPath {
+				if _, err := libFS.Stat(dir); err == nil {
+					if p, err := writeSeasonNFO(seasonTMDB, dir); err == nil {
+						written = append(written, p)
+					}
+				}
+			}
+		}
 
-	if s.ExternalIDs.IMDBId != "" {
-		out += fmt.Sprintf("https://www.imdb.com/title/%v/\n", s.ExternalIDs.IMDBId)
+		// Episodes, removed or renumbered on TMDB, should not stay as broken entries
+		if config.Get().LibraryPruneStaleEpisodes && len(known) > 0 {
+			pruneStaleEpisodes(existingStrm, season.Season, known, absolutes)
+		}
 	}
-	if s.ExternalIDs.TVDBID != "" {
-		out += fmt.Sprintf("https://www.thetvdb.com/?tab=series&id=%v&lid=7\n", s.ExternalIDs.TVDBID)
+	if len(reAddIDs) > 0 {
+		if err := updateBatchDBItem(reAddIDs, StateActive, EpisodeType, showID); err != nil {
+			log.Error(err)
+		}
 	}
 
-	if err := ioutil.WriteFile(p, []byte(out), 0644); err != nil {
-		log.Errorf("Could not write NFO file: %s", err)
-		return err
+	if config.Get().LibraryNFOShows {
+		showNFOPath := filepath.Join(showPath, "tvshow.nfo")
+		if err := writeShowNFO(show, showNFOPath, seasonsCount); err == nil {
+			written = append(written, showNFOPath)
+		}
+	}
+	if force && !changed {
+		touchPath(showPath)
+	}
+
+	updateManifest(filepath.Dir(showPath), &ManifestItem{
+		TMDBID:    show.ID,
+		MediaType: ShowType,
+		Title:     show.Name,
+		Path:      showPath,
+		WrittenAt: time.Now(),
+	})
+
+	return show, written, added, nil
+}
+
+// acquireShowWrite waits for other writer of the show to finish and marks the show as being written,
+// returned function clears the mark, so concurrent writes of one show are serialized
+func acquireShowWrite(showID int) func() {
+	for {
+		lock.Lock()
+		done, busy := writingShows[showID]
+		if !busy {
+			done = make(chan struct{})
+			writingShows[showID] = done
+			lock.Unlock()
+
+			return func() {
+				lock.Lock()
+				delete(writingShows, showID)
+				lock.Unlock()
+				close(done)
+			}
+		}
+		lock.Unlock()
+
+		<-done
+	}
+}
+
+// episodeCode returns SxxExx part of episode strm file name
+func episodeCode(season, episode int) string {
+	return fmt.Sprintf("S%02dE%02d", season, episode)
+}
+
+// episodeStrmName returns episode strm file name, with optional episode title
+func episodeStrmName(showStrm string, season, episode int, title string) string {
+	name := fmt.Sprintf("%s %s", showStrm, episodeCode(season, episode))
+	if config.Get().IncludeEpisodeTitleInFilename && title != "" {
+		name = util.ToFileName(fmt.Sprintf("%s %s", name, title))
+	}
+
+	return name + ".strm"
+}
+
+// firstKeptSeason returns lowest season number, that fits into last N aired seasons of the show
+func firstKeptSeason(show *tmdb.Show, keep int) int {
+	numbers := []int{}
+	for _, season := range show.Seasons {
+		if season == nil || season.Season == 0 || season.EpisodeCount == 0 {
+			continue
+		}
+		if !config.Get().ShowUnairedSeasons {
+			if _, isExpired := util.AirDateWithExpireCheck(season.AirDate, config.Get().ShowEpisodesOnReleaseDay); season.AirDate == "" || isExpired {
+				continue
+			}
+		}
+		numbers = append(numbers, season.Season)
+	}
+
+	if len(numbers) <= keep {
+		return 0
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(numbers)))
+	return numbers[keep-1]
+}
+
+// removeSeasonsBefore removes strm files of regular seasons, that went out of kept seasons window,
+// absolutes map anime episode codes to their seasons
+func removeSeasonsBefore(show *tmdb.Show, existing map[string][]string, minSeason int, absolutes map[string]episodeNumber) {
+	removed := map[int][]int{}
+	for code, paths := range seasonsBefore(existing, minSeason, absolutes) {
+		if removeSeasonEpisode(code, paths, "old season") {
+			addRemovedCode(removed, code, absolutes)
+		}
+		delete(existing, code)
+	}
+	persistPrunedEpisodes(show, removed)
+}
+
+// removeSeasonsOutside removes strm files of regular seasons, that are out of configured season range,
+// range without upper bound has 0 as maxSeason
+func removeSeasonsOutside(show *tmdb.Show, existing map[string][]string, minSeason, maxSeason int, absolutes map[string]episodeNumber) {
+	removed := map[int][]int{}
+	for code, paths := range existing {
+		season, _, ok := codeEpisode(code, absolutes)
+		if !ok || season == 0 {
+			continue
+		}
+		if season >= minSeason && (maxSeason == 0 || season <= maxSeason) {
+			continue
+		}
+
+		if removeSeasonEpisode(code, paths, "out of season range") {
+			addRemovedCode(removed, code, absolutes)
+		}
+		delete(existing, code)
+	}
+	persistPrunedEpisodes(show, removed)
+}
+
+// removeSeasonEpisode removes strm files of the pruned episode, reports if all of them are removed
+func removeSeasonEpisode(code string, paths []string, reason string) bool {
+	ok := true
+	for _, p := range paths {
+		if err := removeEpisodeFile(p); err != nil {
+			log.Warningf("Could not remove episode %s %s: %s", reason, p, err)
+			ok = false
+			continue
+		}
+		log.Debugf("Removed episode %s: %s", reason, p)
+	}
+	return ok
+}
+
+// addRemovedCode adds episode of the code to removed episode numbers by season
+func addRemovedCode(removed map[int][]int, code string, absolutes map[string]episodeNumber) {
+	if season, episode, ok := codeEpisode(code, absolutes); ok {
+		removed[season] = append(removed[season], episode)
+	}
+}
+
+// persistPrunedEpisodes marks pruned episodes as removed in the database, removes emptied season folders
+// and plans Kodi update, so Kodi library drops them as well
+func persistPrunedEpisodes(show *tmdb.Show, removed map[int][]int) {
+	if len(removed) == 0 {
+		return
+	}
+
+	episodes := []*removedEpisode{}
+	for seasonNumber, numbers := range removed {
+		for showPath := range getShowPaths(show) {
+			removeEmptySeasonFolder(showPath, seasonNumber)
+		}
+
+		season := tmdb.GetSeason(show.ID, seasonNumber, config.Get().Language, len(show.Seasons))
+		if season == nil {
+			log.Warningf("Could not get season %d of %s to save removed episodes", seasonNumber, show.Name)
+			continue
+		}
+
+		ids := map[int]int{}
+		for _, e := range season.Episodes {
+			if e != nil {
+				ids[e.EpisodeNumber] = e.ID
+			}
+		}
+		for _, number := range numbers {
+			if ids[number] == 0 {
+				continue
+			}
+			episodes = append(episodes, &removedEpisode{
+				ID:       ids[number],
+				ShowID:   show.ID,
+				ShowName: show.Name,
+				Season:   seasonNumber,
+				Episode:  number,
+			})
+		}
+	}
+
+	persistRemovedEpisodes(episodes)
+	PlanKodiUpdate()
+}
+
+// fetchShowSeasons fetches seasons details concurrently, results keep order of seasons,
+// season, that could not be fetched, is nil
+func fetchShowSeasons(show *tmdb.Show, seasons []*tmdb.Season) []*tmdb.Season {
+	defer perf.ScopeTimer()()
+
+	ret := make([]*tmdb.Season, len(seasons))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, seasonWorkers)
+	for i, season := range seasons {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, season *tmdb.Season) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			ret[i] = tmdb.GetSeason(show.ID, season.Season, config.Get().Language, len(show.Seasons))
+		}(i, season)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, s := range ret {
+		if s == nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Warningf("Could not fetch %d of %d seasons of %s", failed, len(seasons), show.Name)
+	}
+
+	return ret
+}
+
+// pruneStaleEpisodes removes strm files of the season, which episodes are not known to TMDB anymore
+func pruneStaleEpisodes(existing map[string][]string, seasonNumber int, known map[string]bool, absolutes map[string]episodeNumber) {
+	for code, paths := range existing {
+		season, episode, ok := codeEpisode(code, absolutes)
+		if !ok || season != seasonNumber || known[episodeCode(season, episode)] {
+			continue
+		}
+
+		for _, p := range paths {
+			if err := removeEpisodeFile(p); err != nil {
+				log.Warningf("Could not remove stale episode %s: %s", p, err)
+				continue
+			}
+			log.Debugf("Removed stale episode: %s", p)
+		}
+		delete(existing, code)
+	}
+}
+
+// seasonsBefore returns episode strm files of regular seasons before minSeason, keyed by their codes,
+// absolutes map anime episode codes to their seasons
+func seasonsBefore(existing map[string][]string, minSeason int, absolutes map[string]episodeNumber) map[string][]string {
+	ret := map[string][]string{}
+	if minSeason <= 1 {
+		return ret
+	}
+
+	for code, paths := range existing {
+		if season, _, ok := codeEpisode(code, absolutes); !ok || season == 0 || season >= minSeason {
+			continue
+		}
+		ret[code] = paths
+	}
+
+	return ret
+}
+
+// episodeStrmFiles returns episode strm files in show folder and its season folders,
+// grouped by SxxExx code, so files are matched regardless of the episode title part
+func episodeStrmFiles(showPath, showStrm string) map[string][]string {
+	ret := map[string][]string{}
+	collectEpisodeStrmFiles(ret, showPath, showStrm+" ", true)
+	return ret
+}
+
+func collectEpisodeStrmFiles(ret map[string][]string, dir, prefix string, seasons bool) {
+	entries, err := libFS.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() && seasons && isSeasonFolder(name) {
+			collectEpisodeStrmFiles(ret, filepath.Join(dir, name), prefix, false)
+			continue
+		} else if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".strm") {
+			continue
+		}
+
+		code := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".strm")
+		if an, err := strconv.Atoi(strings.TrimPrefix(code, "- ")); err == nil && strings.HasPrefix(code, "- ") {
+			// Anime episode, named by absolute number
+			code = absoluteCode(an)
+		} else if i := strings.Index(code, " "); i != -1 {
+			code = code[:i]
+		}
+		ret[code] = append(ret[code], filepath.Join(dir, name))
+	}
+}
+
+// writeShowNFO writes tvshow.nfo, seasons is a number of seasons with episodes,
+// when it is not known - TMDB number of seasons is used
+func writeShowNFO(s *tmdb.Show, p string, seasons int) error {
+	out, err := renderNFO(showNFOTemplateName, defaultShowNFOTemplate, s, showNFOExtra(s, seasons))
+	if err != nil {
+		log.Errorf("Could not render NFO file: %s", err)
+		return err
+	}
+
+	if isUnchangedFile(p, []byte(out)) {
+		return nil
+	}
+	if err := libFS.WriteFile(p, []byte(out), 0644); err != nil {
+		log.Errorf("Could not write NFO file: %s", err)
+		return err
 	}
 
 	return nil
@@ -685,25 +1633,95 @@ func RemoveMovie(tmdbID int) (*tmdb.Movie, []string, error) {
 		return nil, nil, errors.New("Can't resolve movie")
 	}
 
+	ret, err := removeMovieFolders(movie)
+	if err == nil {
+		libraryChanged(LibraryEvent{Action: ActionRemoved, MediaType: MovieType, TMDBID: movie.ID, Paths: ret})
+	}
+	return movie, ret, err
+}
+
+// RemoveMovies removes many movies from the library at once,
+// returns IDs of movies, that were actually removed
+func RemoveMovies(tmdbIDs []int) ([]int, error) {
+	if err := checkMoviesPath(); err != nil {
+		return nil, err
+	}
+
+	removed := []int{}
+	removedPaths := map[int][]string{}
+	var lastErr error
+	for _, tmdbID := range tmdbIDs {
+		if closer.IsSet() {
+			lastErr = ErrLibraryClosing
+			break
+		}
+
+		movie := tmdb.GetMovieByID(strconv.Itoa(tmdbID), config.Get().StrmLanguage)
+		if movie == nil {
+			lastErr = fmt.Errorf("Can't resolve movie %d", tmdbID)
+			continue
+		}
+
+		paths, err := removeMovieFolders(movie)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		removed = append(removed, tmdbID)
+		removedPaths[tmdbID] = paths
+	}
+
+	if len(removed) > 0 {
+		if err := updateBatchDBItem(removed, StateDeleted, MovieType, 0); err != nil {
+			return removed, err
+		}
+	}
+
+	// Subscribers get each removed movie, same as for single removals
+	for _, tmdbID := range removed {
+		libraryChanged(LibraryEvent{Action: ActionRemoved, MediaType: MovieType, TMDBID: tmdbID, Paths: removedPaths[tmdbID]})
+	}
+
+	return removed, lastErr
+}
+
+// removeMovieFolders removes all folders of the movie from disk,
+// or just movie files for flat library layout
+func removeMovieFolders(movie *tmdb.Movie) ([]string, error) {
 	paths := getMoviePaths(movie)
 
 	if len(paths) == 0 {
 		log.Warningf("Cannot find directories with strm files")
-		return movie, nil, errors.New("LOCALIZE[30282]")
+		return nil, errors.New("LOCALIZE[30282]")
 	}
 	ret := []string{}
 	for path := range paths {
-		if err := os.RemoveAll(path); err != nil {
+		if isFlatMovieFile(path) {
+			if err := removeFlatMovieFiles(path, movie.ID); err != nil {
+				log.Error(err)
+				return nil, err
+			}
+
+			ret = append(ret, path)
+			log.Warningf("File %s removed from disk", path)
+			continue
+		}
+
+		if err := removeTitleDir(path, MovieType, movie.ID); err != nil {
 			log.Error(err)
-			return movie, nil, err
+			return nil, err
 		}
 
 		ret = append(ret, path)
 		log.Warningf("Directory %s removed from disk", path)
 	}
 
+	for _, root := range MoviesLibraryPaths() {
+		removeFromManifest(root, movie.ID, MovieType)
+	}
+
 	log.Warningf("%s removed from library", movie.Title)
-	return movie, ret, nil
+	return ret, nil
 }
 
 // RemoveShow removes show from the library
@@ -730,7 +1748,7 @@ func RemoveShow(tmdbID string) (*tmdb.Show, []string, error) {
 	}
 	ret := []string{}
 	for path := range paths {
-		if err := os.RemoveAll(path); err != nil {
+		if err := removeTitleDir(path, ShowType, show.ID); err != nil {
 			log.Error(err)
 			return show, nil, err
 		}
@@ -739,7 +1757,12 @@ func RemoveShow(tmdbID string) (*tmdb.Show, []string, error) {
 		log.Warningf("Directory %s removed from disk", path)
 	}
 
+	for _, root := range ShowsLibraryPaths() {
+		removeFromManifest(root, show.ID, ShowType)
+	}
+
 	log.Warningf("%s removed from library", show.Name)
+	libraryChanged(LibraryEvent{Action: ActionRemoved, MediaType: ShowType, TMDBID: show.ID, Paths: ret})
 
 	return show, ret, nil
 }
@@ -755,35 +1778,42 @@ func RemoveEpisode(tmdbID int, showID int, seasonNumber int, episodeNumber int)
 		return errors.New("Unable to find show to remove episode")
 	}
 
-	showName := show.OriginalName
-	if config.Get().StrmLanguage != config.Get().Language && show.Name != "" {
-		showName = show.Name
+	episodeStrm := fmt.Sprintf("%s %s", showFolderName(show), episodeCode(seasonNumber, episodeNumber))
+	episodePaths, err := removeEpisodeFiles(show, seasonNumber, episodeNumber)
+	if err != nil {
+		return err
 	}
-
-	showPath := util.ToFileName(fmt.Sprintf("%s (%s)", showName, strings.Split(show.FirstAirDate, "-")[0]))
-	episodeStrm := fmt.Sprintf("%s S%02dE%02d.strm", showPath, seasonNumber, episodeNumber)
-	episodePath := filepath.Join(ShowsLibraryPath(), showPath, episodeStrm)
-
-	alreadyRemoved := false
-	if _, err := os.Stat(episodePath); err != nil {
-		alreadyRemoved = true
-	}
-	if !alreadyRemoved {
-		if err := os.Remove(episodePath); err != nil {
-			return err
-		}
+	if len(episodePaths) > 0 {
+		episodeStrm = strings.TrimSuffix(filepath.Base(episodePaths[0]), ".strm")
 	}
 
-	removedEpisodes <- &removedEpisode{
+	alreadyRemoved := len(episodePaths) == 0
+
+	episode := &removedEpisode{
 		ID:       tmdbID,
 		ShowID:   showID,
 		ShowName: show.Name,
 		Season:   seasonNumber,
 		Episode:  episodeNumber,
 	}
+	select {
+	case removedEpisodes <- episode:
+	case <-closer.C():
+		// Debouncer is stopped, so save the state right away
+		persistRemovedEpisodes([]*removedEpisode{episode})
+	}
 
 	if !alreadyRemoved {
 		log.Noticef("%s removed from library", episodeStrm)
+		libraryChanged(LibraryEvent{
+			Action:    ActionRemoved,
+			MediaType: EpisodeType,
+			TMDBID:    tmdbID,
+			ShowID:    showID,
+			Season:    seasonNumber,
+			Episode:   episodeNumber,
+			Paths:     episodePaths,
+		})
 	} else {
 		return errors.New("Nothing left to remove from Elementum")
 	}
@@ -791,6 +1821,204 @@ func RemoveEpisode(tmdbID int, showID int, seasonNumber int, episodeNumber int)
 	return nil
 }
 
+// RemoveSeason removes all episodes of a season from the library
+func RemoveSeason(tmdbID int, seasonNumber int) error {
+	if err := checkShowsPath(); err != nil {
+		return err
+	}
+
+	show := tmdb.GetShow(tmdbID, config.Get().StrmLanguage)
+	if show == nil {
+		return errors.New("Unable to find show to remove season")
+	}
+
+	// Episodes are removed only when they can be marked as removed, otherwise next update writes them back
+	season := tmdb.GetSeason(tmdbID, seasonNumber, config.Get().Language, len(show.Seasons))
+	if season == nil {
+		return fmt.Errorf("Unable to find season %d of %s to remove", seasonNumber, show.Name)
+	}
+	episodeIDs := map[int]int{}
+	for _, e := range season.Episodes {
+		if e != nil {
+			episodeIDs[e.EpisodeNumber] = e.ID
+		}
+	}
+
+	// Anime episodes of the season are matched by their absolute numbers
+	absCodes := map[string]int{}
+	if isAnimeAbsolute(show) {
+		absolute := showAbsoluteNumbers(show)
+		for episode := range episodeIDs {
+			if an := absolute(seasonNumber, episode); an > 0 {
+				absCodes[absoluteCode(an)] = episode
+			}
+		}
+	}
+
+	removed := 0
+	removedPaths := map[int][]string{}
+	for showPath := range getShowPaths(show) {
+		for code, paths := range episodeStrmFiles(showPath, filepath.Base(showPath)) {
+			var season, episode int
+			if e, ok := absCodes[code]; ok {
+				season, episode = seasonNumber, e
+			} else if n, _ := fmt.Sscanf(code, "S%dE%d", &season, &episode); n != 2 || season != seasonNumber {
+				continue
+			}
+
+			for _, p := range paths {
+				if err := removeEpisodeFile(p); err != nil {
+					return err
+				}
+			}
+			removed++
+			removedPaths[episode] = append(removedPaths[episode], paths...)
+
+			if episodeIDs[episode] == 0 {
+				continue
+			}
+
+			e := &removedEpisode{
+				ID:       episodeIDs[episode],
+				ShowID:   tmdbID,
+				ShowName: show.Name,
+				Season:   seasonNumber,
+				Episode:  episode,
+			}
+			select {
+			case removedEpisodes <- e:
+			case <-closer.C():
+				persistRemovedEpisodes([]*removedEpisode{e})
+			}
+		}
+		removeEmptySeasonFolder(showPath, seasonNumber)
+	}
+
+	if removed == 0 {
+		return errors.New("Nothing left to remove from Elementum")
+	}
+
+	for episode, paths := range removedPaths {
+		libraryChanged(LibraryEvent{
+			Action:    ActionRemoved,
+			MediaType: EpisodeType,
+			TMDBID:    episodeIDs[episode],
+			ShowID:    tmdbID,
+			Season:    seasonNumber,
+			Episode:   episode,
+			Paths:     paths,
+		})
+	}
+
+	log.Noticef("Season %d of %s removed from library", seasonNumber, show.Name)
+	return nil
+}
+
+// removeEpisodeFiles removes strm files of the episode from all folders of the show,
+// returns paths of removed files
+func removeEpisodeFiles(show *tmdb.Show, seasonNumber int, episodeNumber int) ([]string, error) {
+	// Show folder could be created under another title, so all folders of the show are checked
+	code := episodeCode(seasonNumber, episodeNumber)
+	absCode := ""
+	if isAnimeAbsolute(show) {
+		if an := showAbsoluteNumbers(show)(seasonNumber, episodeNumber); an > 0 {
+			absCode = absoluteCode(an)
+		}
+	}
+
+	episodePaths := []string{}
+	for showPath := range getShowPaths(show) {
+		files := episodeStrmFiles(showPath, filepath.Base(showPath))
+		episodePaths = append(episodePaths, files[code]...)
+		if absCode != "" {
+			episodePaths = append(episodePaths, files[absCode]...)
+		}
+	}
+
+	for _, episodePath := range episodePaths {
+		if err := removeEpisodeFile(episodePath); err != nil {
+			return nil, err
+		}
+		removeEmptySeasonFolder(showPathOf(episodePath), seasonNumber)
+	}
+	return episodePaths, nil
+}
+
+// persistRemovedEpisodes marks episodes as removed in the database, without any dialogs
+func persistRemovedEpisodes(episodes []*removedEpisode) {
+	if len(episodes) == 0 {
+		return
+	}
+
+	shows := map[int][]int{}
+	for _, episode := range episodes {
+		shows[episode.ShowID] = append(shows[episode.ShowID], episode.ID)
+	}
+
+	for showID, tmdbIDs := range shows {
+		if err := updateBatchDBItem(tmdbIDs, StateDeleted, EpisodeType, showID); err != nil {
+			log.Errorf("Could not save removed episodes of show %d: %s", showID, err)
+		}
+	}
+}
+
+// isLibraryRoot checks if path is a library root or its parent
+func isLibraryRoot(path string) bool {
+	if path == "" {
+		return true
+	}
+	path = filepath.Clean(path)
+
+	roots := append(append(LibraryPaths(), MoviesLibraryPaths()...), ShowsLibraryPaths()...)
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(path, filepath.Clean(root)); err != nil || rel == "." || !strings.HasPrefix(rel, "..") {
+			log.Errorf("Refusing to remove %s, since it is a library root or its parent", path)
+			return true
+		}
+	}
+
+	return false
+}
+
+// safeRemoveAll removes directory with its content, but refuses to remove library roots
+// or directories above them, and asks for confirmation if directory has too many subdirectories,
+// to avoid losing the whole library because of path resolving errors
+func safeRemoveAll(path string) error {
+	if isLibraryRoot(path) {
+		return ErrUnsafeRemoval
+	}
+	path = filepath.Clean(path)
+
+	if limit := config.Get().LibraryRemoveMaxDirs; limit > 0 {
+		if count := countSubdirs(path); count > limit {
+			if !isProfileAllowed() || !xbmc.DialogConfirm("Elementum", fmt.Sprintf("LOCALIZE[30675];;%s;;%d", path, count)) {
+				log.Errorf("Refusing to remove %s with %d subdirectories", path, count)
+				return ErrUnsafeRemoval
+			}
+		}
+	}
+
+	return libFS.RemoveAll(path)
+}
+
+func countSubdirs(dir string) (count int) {
+	entries, err := libFS.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			count += 1 + countSubdirs(filepath.Join(dir, e.Name()))
+		}
+	}
+
+	return
+}
+
 //
 // Database updates
 //
@@ -802,12 +2030,11 @@ func updateDBItem(tmdbID int, state int, mediaType int, showID int) error {
 
 	defer perf.ScopeTimer()()
 
-	li := database.LibraryItem{
-		ID:        tmdbID,
-		MediaType: mediaType,
-		ShowID:    showID,
-		State:     state,
-	}
+	li := getDBItem(database.GetStormDB(), tmdbID)
+	li.MediaType = mediaType
+	li.ShowID = showID
+	li.State = state
+	li.UpdatedAt = time.Now()
 	if err := database.GetStormDB().Save(&li); err != nil {
 		log.Debugf("updateDBItem failed: %s", err)
 		return err
@@ -824,13 +2051,13 @@ func updateBatchDBItem(tmdbIds []int, state int, mediaType int, showID int) erro
 	}
 	defer tx.Rollback()
 
+	now := time.Now()
 	for _, id := range tmdbIds {
-		li := database.LibraryItem{
-			ID:        id,
-			MediaType: mediaType,
-			ShowID:    showID,
-			State:     state,
-		}
+		li := getDBItem(tx, id)
+		li.MediaType = mediaType
+		li.ShowID = showID
+		li.State = state
+		li.UpdatedAt = now
 		err = tx.Save(&li)
 		if err != nil {
 			return err
@@ -840,6 +2067,57 @@ func updateBatchDBItem(tmdbIds []int, state int, mediaType int, showID int) erro
 	return tx.Commit()
 }
 
+// ListLibraryItems returns library items of the media type in the state,
+// -1 for mediaType or state matches any value
+func ListLibraryItems(mediaType int, state int) ([]database.LibraryItem, error) {
+	return listLibraryItems(mediaType, state)
+}
+
+// RecentlyAdded returns active library movies and shows, most recently added first,
+// items with unknown addition time, added before it was tracked, come last
+func RecentlyAdded(limit int) ([]database.LibraryItem, error) {
+	query := database.GetStormDB().Select(q.Or(q.Eq("MediaType", MovieType), q.Eq("MediaType", ShowType)), q.Eq("State", StateActive)).OrderBy("AddedAt").Reverse()
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var lis []database.LibraryItem
+	if err := query.Find(&lis); err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	return lis, nil
+}
+
+// listLibraryItems returns library items of the media type in the state, matching extra matchers
+func listLibraryItems(mediaType int, state int, matchers ...q.Matcher) ([]database.LibraryItem, error) {
+	if mediaType != -1 {
+		matchers = append(matchers, q.Eq("MediaType", mediaType))
+	}
+	if state != -1 {
+		matchers = append(matchers, q.Eq("State", state))
+	}
+
+	var lis []database.LibraryItem
+	if err := database.GetStormDB().Select(matchers...).Find(&lis); err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	return lis, nil
+}
+
+// getDBItem returns stored library item, to keep its other fields on update,
+// or a new one if it is not yet stored
+func getDBItem(db storm.Node, tmdbID int) database.LibraryItem {
+	var li database.LibraryItem
+	if err := db.One("ID", tmdbID, &li); err != nil {
+		li = database.LibraryItem{AddedAt: time.Now()}
+	}
+	li.ID = tmdbID
+
+	return li
+}
+
 func deleteDBItem(tmdbID int, mediaType int, removal bool) error {
 	defer perf.ScopeTimer()()
 
@@ -882,7 +2160,36 @@ func deleteDBItem(tmdbID int, mediaType int, removal bool) error {
 // 	return nil
 // }
 
-func wasRemoved(id int, mediaType int) (wasRemoved bool) {
+// FreezeItem marks library item as frozen, so automatic updates would skip it,
+// while it still can be updated manually with force
+func FreezeItem(tmdbID, mediaType int, frozen bool) error {
+	var li database.LibraryItem
+	if err := database.GetStormDB().Select(q.Eq("ID", tmdbID), q.Eq("MediaType", mediaType)).First(&li); err != nil {
+		log.Debugf("Cannot find item to freeze: %s", err)
+		return err
+	}
+
+	li.Frozen = frozen
+	if err := database.GetStormDB().Save(&li); err != nil {
+		log.Debugf("Cannot update frozen item: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+func isFrozen(id int, mediaType int) bool {
+	var li database.LibraryItem
+	if err := database.GetStormDB().Select(q.Eq("ID", id), q.Eq("MediaType", mediaType), q.Eq("Frozen", true)).First(&li); err == nil && li.ID != 0 {
+		log.Debugf("mediaType=%d id=%d is frozen in database", mediaType, id)
+		return true
+	}
+
+	return false
+}
+
+// WasRemoved checks if item is marked as removed from the library by the user
+func WasRemoved(id int, mediaType int) (wasRemoved bool) {
 	defer perf.ScopeTimer()()
 
 	var li database.LibraryItem
@@ -894,6 +2201,35 @@ func wasRemoved(id int, mediaType int) (wasRemoved bool) {
 	return false
 }
 
+// removedMovieSync decides how list sync handles a movie, removed by the user:
+// scheduled updates skip it, while explicit list add writes it again, forcing the write
+func removedMovieSync(updating, isRemoved bool) (skip, force bool) {
+	return updating && isRemoved, isRemoved
+}
+
+// WhichRemoved checks many items at once, returns only ids, marked as removed from the library
+func WhichRemoved(ids []int, mediaType int) map[int]bool {
+	defer perf.ScopeTimer()()
+
+	ret := map[int]bool{}
+	if len(ids) == 0 {
+		return ret
+	}
+
+	var lis []database.LibraryItem
+	if err := database.GetStormDB().Select(q.In("ID", ids), q.Eq("MediaType", mediaType), q.Eq("State", StateDeleted)).Find(&lis); err != nil {
+		if err != storm.ErrNotFound {
+			log.Debugf("Could not check removed items: %s", err)
+		}
+		return ret
+	}
+
+	for _, li := range lis {
+		ret[li.ID] = true
+	}
+	return ret
+}
+
 //
 // Maintenance
 //
@@ -902,6 +2238,9 @@ func wasRemoved(id int, mediaType int) (wasRemoved bool) {
 func CloseLibrary() {
 	log.Info("Closing library...")
 	closer.Set()
+
+	// Wait for pending removed episodes to be saved before database is closed
+	debouncerWG.Wait()
 }
 
 // ClearPageCache deletes cached page listings
@@ -950,6 +2289,24 @@ func ClearTmdbCache() {
 	xbmc.Refresh()
 }
 
+// ClearTmdbCacheForID deletes cached tmdb data of a single movie or show in all languages,
+// for shows cached seasons, episodes and images are deleted as well
+func ClearTmdbCacheForID(tmdbID int, mediaType int) {
+	cacheDB := database.GetCache()
+	if cacheDB == nil {
+		return
+	}
+
+	if mediaType == MovieType {
+		cacheDB.DeleteWithPrefix(database.CommonBucket, []byte(fmt.Sprintf("%smovie.%d.", cache.TMDBKey, tmdbID)))
+		return
+	}
+
+	for _, prefix := range []string{"show", "season", "episode"} {
+		cacheDB.DeleteWithPrefix(database.CommonBucket, []byte(fmt.Sprintf("%s%s.%d.", cache.TMDBKey, prefix, tmdbID)))
+	}
+}
+
 //
 // Utilities
 // 		mainly copied from api/routes to skip cycle imports
@@ -975,16 +2332,103 @@ func URLQuery(route string, query ...string) string {
 	return route + "?" + v.Encode()
 }
 
+// addLinkQuery adds query parameter to a link, that could already have a query
+func addLinkQuery(link, key, value string) string {
+	sep := "?"
+	if strings.Contains(link, "?") {
+		sep = "&"
+	}
+	return link + sep + url.Values{key: []string{value}}.Encode()
+}
+
+// URLForStrm returns plugin or HTTP link, depending on configured strm URL mode
+func URLForStrm(pattern string, args ...interface{}) string {
+	if config.Get().StrmURLMode == StrmURLHTTP {
+		return URLForHTTP(pattern, args...)
+	}
+	return URLForXBMC(pattern, args...)
+}
+
+// moviePlayLink returns play link for movie strm file, with optional quality hint
+func moviePlayLink(tmdbID int) string {
+	link := URLForStrm("/library/movie/play/%d", tmdbID)
+	if quality := config.Get().MovieForceQuality; quality != "" {
+		return URLQuery(link, "quality", quality)
+	}
+	return link
+}
+
+// episodePlayLink returns play link for episode strm file, with optional quality hint
+func episodePlayLink(showID, season, episode int) string {
+	link := URLForStrm("/library/show/play/%d/%d/%d", showID, season, episode)
+	if quality := config.Get().ShowForceQuality; quality != "" {
+		return URLQuery(link, "quality", quality)
+	}
+	return link
+}
+
+// confirmWithTimeout shows focused confirmation dialog, but if user does not answer
+// in configured time - closes it and returns configured default answer,
+// so background maintenance is not stalled on unattended box
+func confirmWithTimeout(message string) bool {
+	if !isProfileAllowed() {
+		log.Infof("Confirmation suppressed for current Kodi profile, using default answer: %t", config.Get().ConfirmTimeoutDefault)
+		return config.Get().ConfirmTimeoutDefault
+	}
+
+	timeout := config.Get().ConfirmTimeoutSeconds
+	if timeout <= 0 {
+		return xbmc.DialogConfirmFocused("Elementum", message)
+	}
+
+	answer := make(chan bool, 1)
+	go func() {
+		answer <- xbmc.DialogConfirmFocused("Elementum", message)
+	}()
+
+	select {
+	case res := <-answer:
+		return res
+	case <-time.After(time.Duration(timeout) * time.Second):
+		xbmc.CloseAllConfirmDialogs()
+		log.Infof("No answer for confirmation in %d seconds, using default answer: %t", timeout, config.Get().ConfirmTimeoutDefault)
+		return config.Get().ConfirmTimeoutDefault
+	}
+}
+
 //
 // Movie internals
 //
 
 // SyncMoviesList updates trakt movie collections in cache
 func SyncMoviesList(listID string, updating bool, isUpdateNeeded bool) (err error) {
+	return SyncMoviesListContext(context.Background(), listID, updating, isUpdateNeeded)
+}
+
+// SyncMoviesListContext is SyncMoviesList, that stops between movies when ctx is cancelled
+func SyncMoviesListContext(ctx context.Context, listID string, updating bool, isUpdateNeeded bool) (err error) {
+	return syncMoviesList(ctx, "", listID, updating, isUpdateNeeded, nil)
+}
+
+// PreviewMoviesList returns movies, that SyncMoviesList would write, without writing them
+func PreviewMoviesList(listID string, updating bool) ([]SyncPreviewItem, error) {
+	preview := []SyncPreviewItem{}
+	err := syncMoviesList(context.Background(), "", listID, updating, false, &preview)
+	return preview, err
+}
+
+// syncMoviesList writes movies of the list, with non-nil preview movies are only collected into it
+func syncMoviesList(ctx context.Context, user string, listID string, updating bool, isUpdateNeeded bool, preview *[]SyncPreviewItem) (err error) {
 	if err = checkMoviesPath(); err != nil {
 		return
+	} else if IsMediaTypePaused(MovieType) {
+		log.Debugf("Trakt sync movies %s skipped, movie updates are paused", listID)
+		return
 	}
 
+	defer beginManifestBatch()()
+	defer beginFolderIndex()()
+
 	started := time.Now()
 	defer func() {
 		log.Debugf("Trakt sync movies %s finished in %s", listID, time.Since(started))
@@ -995,42 +2439,95 @@ func SyncMoviesList(listID string, updating bool, isUpdateNeeded bool) (err erro
 
 	switch listID {
 	case "watchlist":
-		movies, err = trakt.WatchlistMovies(isUpdateNeeded)
 		label = "LOCALIZE[30254]"
 	case "collection":
-		movies, err = trakt.CollectionMovies(isUpdateNeeded)
 		label = "LOCALIZE[30257]"
 	default:
-		movies, err = trakt.ListItemsMovies("", listID, isUpdateNeeded)
 		label = "LOCALIZE[30263]"
 	}
 
-	if err != nil {
-		log.Error(err)
-		return
-	}
+	err = withTraktRetry("movies "+listID, func() (err error) {
+		movies, err = traktListMovies(user, listID, isUpdateNeeded)
+		return
+	})
+
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	// Resolve missing TMDB ids through IMDB ids, if provided, all at once
+	refs := []ExternalRef{}
+	for _, movie := range movies {
+		if movie.Movie.IDs.TMDB == 0 && len(movie.Movie.IDs.IMDB) > 0 {
+			refs = append(refs, ExternalRef{MediaType: MovieType, Source: "imdb_id", ID: movie.Movie.IDs.IMDB})
+		}
+	}
+	if err := WarmResolveCache(refs); err != nil {
+		return err
+	}
+	resolved := cachedExternalIDs(refs)
+
+	moviesLastUpdates := map[int]time.Time{}
+
+	// Keep tracking of processed movies to avoid checking all of them again.
+	cacheStore.Get(cache.LibraryMoviesLastUpdatesKey, &moviesLastUpdates)
+
+	// Interrupted sync continues from the last checkpoint
+	traktIDs := make([]int, len(movies))
+	for n, movie := range movies {
+		traktIDs[n] = movie.Movie.IDs.Trakt
+	}
+	var movieIDs []int
+	titles := map[int]ItemTitle{}
+	start := 0
+	cursor := loadSyncCursor(MovieType, user, listID)
+	if preview == nil {
+		if start = cursor.resumeIndex(traktIDs); start > 0 {
+			log.Infof("Resuming Trakt sync of movies %s from item %d of %d", listID, start+1, len(movies))
+			movieIDs = append(movieIDs, cursor.Written...)
+		}
+	}
+
+	current := map[int]bool{}
+	isComplete := true
+	for n, movie := range movies {
+		if err := ctx.Err(); err != nil {
+			return err
+		} else if closer.IsSet() {
+			return ErrLibraryClosing
+		}
+		if preview == nil {
+			reportProgress(Progress{Operation: ProgressSyncMovies, MediaType: MovieType, ListID: listID, Current: n + 1, Total: len(movies)})
+			if n > start && n%syncCursorInterval == 0 {
+				cursor.checkpoint(n, traktIDs, movieIDs, nil)
+				cacheStore.Set(cache.LibraryMoviesLastUpdatesKey, &moviesLastUpdates, cache.LibraryMoviesLastUpdatesExpire)
+			}
+		}
 
-	var movieIDs []int
-	for _, movie := range movies {
 		title := movie.Movie.Title
-		// Try to resolve TMDB id through IMDB id, if provided
 		if movie.Movie.IDs.TMDB == 0 && len(movie.Movie.IDs.IMDB) > 0 {
-			r := tmdb.Find(movie.Movie.IDs.IMDB, "imdb_id")
-			if r != nil && len(r.MovieResults) > 0 {
-				movie.Movie.IDs.TMDB = r.MovieResults[0].ID
-			}
+			movie.Movie.IDs.TMDB = resolved[ExternalRef{Source: "imdb_id", ID: movie.Movie.IDs.IMDB}.Key()]
 		}
 
 		if movie.Movie.IDs.TMDB == 0 {
 			log.Warningf("Missing TMDB ID for %s", title)
+			isComplete = false
+			continue
+		}
+		current[movie.Movie.IDs.TMDB] = true
+		if n < start {
 			continue
 		}
 
+		// Scheduled sync skips movies, written since their last Trakt update, without TMDB lookup and write
 		tmdbID := strconv.Itoa(movie.Movie.IDs.TMDB)
+		if t, ok := moviesLastUpdates[movie.Movie.IDs.Trakt]; ok && updating && !t.Before(movie.Movie.UpdatedAt) {
+			continue
+		}
 
-		// FIXME: 'updating' is always passed as false, so wasRemoved check is always ignored.
-		// also writeMovieStrm now has wasRemoved check.
-		if updating && wasRemoved(movie.Movie.IDs.TMDB, MovieType) {
+		skip, force := removedMovieSync(updating, WasRemoved(movie.Movie.IDs.TMDB, MovieType))
+		if skip {
 			continue
 		}
 
@@ -1039,21 +2536,67 @@ func SyncMoviesList(listID string, updating bool, isUpdateNeeded bool) (err erro
 			continue
 		}
 
-		if _, err := writeMovieStrm(tmdbID, false); err != nil {
+		if hasImportFilter() {
+			if reason := movieSkipReason(tmdb.GetMovieByID(tmdbID, config.Get().Language)); reason != "" {
+				recordSkip(MovieType, movie.Movie.IDs.TMDB, title, reason)
+				continue
+			}
+		}
+
+		if preview != nil {
+			*preview = append(*preview, SyncPreviewItem{TMDBID: movie.Movie.IDs.TMDB, Title: title})
+			continue
+		}
+
+		written, _, err := writeMovieStrm(tmdbID, force)
+		if err != nil {
 			continue
 		}
+		moviesLastUpdates[movie.Movie.IDs.Trakt] = movie.Movie.UpdatedAt
 
 		movieIDs = append(movieIDs, movie.Movie.IDs.TMDB)
+		titles[written.ID] = movieItemTitle(written)
 	}
+	if preview != nil {
+		return nil
+	}
+
+	// Cleanup unused map items
+	listed := map[int]bool{}
+	for _, id := range traktIDs {
+		listed[id] = true
+	}
+	for k := range moviesLastUpdates {
+		if !listed[k] {
+			delete(moviesLastUpdates, k)
+		}
+	}
+	cacheStore.Set(cache.LibraryMoviesLastUpdatesKey, &moviesLastUpdates, cache.LibraryMoviesLastUpdatesExpire)
 
 	if err := updateBatchDBItem(movieIDs, StateActive, MovieType, 0); err != nil {
 		return err
 	}
+	if err := setItemTitles(MovieType, titles); err != nil {
+		log.Warningf("Could not save titles of movies: %s", err)
+	}
+	cursor.done()
+	if err := setItemsOrigin(movieIDs, listOrigin(user, listID), false); err != nil {
+		log.Warningf("Could not save origin of movies: %s", err)
+	}
+
+	// Movie without TMDB ID could be one of added ones, so nothing is removed then
+	if isComplete {
+		removeDeletedListItems(MovieType, listOrigin(user, listID), current)
+	}
 
-	if !updating && len(movieIDs) > 0 {
-		log.Noticef("Movies list (%s) added", listID)
-		if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30277];;%s", label))) {
-			xbmc.VideoLibraryScan()
+	if len(movieIDs) > 0 {
+		if !updating {
+			log.Noticef("Movies list (%s) added", listID)
+		}
+		if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && confirmWithTimeout(fmt.Sprintf("LOCALIZE[30277];;%s", label))) {
+			for _, root := range MoviesLibraryPaths() {
+				RequestScan(root)
+			}
 		}
 	}
 	return nil
@@ -1065,10 +2608,33 @@ func SyncMoviesList(listID string, updating bool, isUpdateNeeded bool) (err erro
 
 // SyncShowsList updates trakt collections in cache
 func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error) {
+	return SyncShowsListContext(context.Background(), listID, updating, isUpdateNeeded)
+}
+
+// SyncShowsListContext is SyncShowsList, that stops between shows when ctx is cancelled
+func SyncShowsListContext(ctx context.Context, listID string, updating bool, isUpdateNeeded bool) (err error) {
+	return syncShowsList(ctx, "", listID, updating, isUpdateNeeded, nil)
+}
+
+// PreviewShowsList returns shows, that SyncShowsList would write, without writing them
+func PreviewShowsList(listID string, updating bool) ([]SyncPreviewItem, error) {
+	preview := []SyncPreviewItem{}
+	err := syncShowsList(context.Background(), "", listID, updating, false, &preview)
+	return preview, err
+}
+
+// syncShowsList writes shows of the list, with non-nil preview shows are only collected into it
+func syncShowsList(ctx context.Context, user string, listID string, updating bool, isUpdateNeeded bool, preview *[]SyncPreviewItem) (err error) {
 	if err = checkShowsPath(); err != nil {
 		return err
+	} else if IsMediaTypePaused(ShowType) {
+		log.Debugf("Trakt sync shows %s skipped, show updates are paused", listID)
+		return nil
 	}
 
+	defer beginManifestBatch()()
+	defer beginFolderIndex()()
+
 	started := time.Now()
 	defer func() {
 		log.Debugf("Trakt sync shows %s finished in %s", listID, time.Since(started))
@@ -1078,25 +2644,25 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 	var shows []*trakt.Shows
 	var previous []*trakt.Shows
 	var current []*trakt.Shows
+	var currentErr error
 
 	switch listID {
 	case "watchlist":
 		previous, _ = trakt.PreviousWatchlistShows()
-		current, _ = trakt.WatchlistShows(isUpdateNeeded)
-
 		label = "LOCALIZE[30254]"
 	case "collection":
 		previous, _ = trakt.PreviousCollectionShows()
-		current, _ = trakt.CollectionShows(isUpdateNeeded)
-
 		label = "LOCALIZE[30257]"
 	default:
 		previous, _ = trakt.PreviousListItemsShows(listID)
-		current, _ = trakt.ListItemsShows("", listID, isUpdateNeeded)
-
 		label = "LOCALIZE[30263]"
 	}
 
+	currentErr = withTraktRetry("shows "+listID, func() (err error) {
+		current, err = traktListShows(user, listID, isUpdateNeeded)
+		return
+	})
+
 	// For first run we will try to write all shows, not only the delta
 	if !IsTraktInitialized {
 		shows = current
@@ -1115,28 +2681,77 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 	// Keep tracking of processed shows to avoid re-writing and checking all of them again.
 	cacheStore.Get(cache.LibraryShowsLastUpdatesKey, &showsLastUpdates)
 	defer func() {
-		cacheStore.Set(cache.LibraryShowsLastUpdatesKey, &showsLastUpdates, cache.LibraryShowsLastUpdatesExpire)
+		if preview == nil {
+			cacheStore.Set(cache.LibraryShowsLastUpdatesKey, &showsLastUpdates, cache.LibraryShowsLastUpdatesExpire)
+		}
 	}()
 
-	var showIDs []int
+	// Resolve missing TMDB ids through IMDB ids, and then through TVDB ids, all at once
+	refs := []ExternalRef{}
 	for _, show := range shows {
-		title := show.Show.Title
-		// Try to resolve TMDB id through IMDB id, if provided
-		if show.Show.IDs.TMDB == 0 {
-			if len(show.Show.IDs.IMDB) > 0 {
-				r := tmdb.Find(show.Show.IDs.IMDB, "imdb_id")
-				if r != nil && len(r.TVResults) > 0 {
-					show.Show.IDs.TMDB = r.TVResults[0].ID
-				}
-			}
-			if show.Show.IDs.TMDB == 0 && show.Show.IDs.TVDB != 0 {
-				r := tmdb.Find(strconv.Itoa(show.Show.IDs.TVDB), "tvdb_id")
-				if r != nil && len(r.TVResults) > 0 {
-					show.Show.IDs.TMDB = r.TVResults[0].ID
-				}
+		if show.Show.IDs.TMDB == 0 && len(show.Show.IDs.IMDB) > 0 {
+			refs = append(refs, ExternalRef{MediaType: ShowType, Source: "imdb_id", ID: show.Show.IDs.IMDB})
+		}
+	}
+	if err := WarmResolveCache(refs); err != nil {
+		return err
+	}
+	resolved := cachedExternalIDs(refs)
+
+	refs = []ExternalRef{}
+	for _, show := range shows {
+		if show.Show.IDs.TMDB == 0 && len(show.Show.IDs.IMDB) > 0 {
+			show.Show.IDs.TMDB = resolved[ExternalRef{Source: "imdb_id", ID: show.Show.IDs.IMDB}.Key()]
+		}
+		if show.Show.IDs.TMDB == 0 && show.Show.IDs.TVDB != 0 {
+			refs = append(refs, ExternalRef{MediaType: ShowType, Source: "tvdb_id", ID: strconv.Itoa(show.Show.IDs.TVDB)})
+		}
+	}
+	if err := WarmResolveCache(refs); err != nil {
+		return err
+	}
+	resolved = cachedExternalIDs(refs)
+
+	// Interrupted sync continues from the last checkpoint
+	traktIDs := make([]int, len(shows))
+	for n, show := range shows {
+		traktIDs[n] = show.Show.IDs.Trakt
+	}
+	var showIDs []int
+	titles := map[int]ItemTitle{}
+	var newShowIDs []int
+	start := 0
+	cursor := loadSyncCursor(ShowType, user, listID)
+	if preview == nil {
+		if start = cursor.resumeIndex(traktIDs); start > 0 {
+			log.Infof("Resuming Trakt sync of shows %s from item %d of %d", listID, start+1, len(shows))
+			showIDs = append(showIDs, cursor.Written...)
+			newShowIDs = append(newShowIDs, cursor.New...)
+		}
+	}
+
+	for n, show := range shows {
+		if n < start {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		} else if closer.IsSet() {
+			return ErrLibraryClosing
+		}
+		if preview == nil {
+			reportProgress(Progress{Operation: ProgressSyncShows, MediaType: ShowType, ListID: listID, Current: n + 1, Total: len(shows)})
+			if n > start && n%syncCursorInterval == 0 {
+				cursor.checkpoint(n, traktIDs, showIDs, newShowIDs)
+				cacheStore.Set(cache.LibraryShowsLastUpdatesKey, &showsLastUpdates, cache.LibraryShowsLastUpdatesExpire)
 			}
 		}
 
+		title := show.Show.Title
+		if show.Show.IDs.TMDB == 0 && show.Show.IDs.TVDB != 0 {
+			show.Show.IDs.TMDB = resolved[ExternalRef{Source: "tvdb_id", ID: strconv.Itoa(show.Show.IDs.TVDB)}.Key()]
+		}
+
 		if show.Show.IDs.TMDB == 0 {
 			log.Warningf("Missing TMDB ID for %s", title)
 			continue
@@ -1152,11 +2767,33 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 			continue
 		}
 
-		if _, err := writeShowStrm(show.Show.IDs.TMDB, false, false); err != nil {
+		// Only new shows are filtered, already added ones should keep updating
+		if hasImportFilter() && !uid.IsDuplicateShow(tmdbID) {
+			if reason := showSkipReason(tmdb.GetShowByID(tmdbID, config.Get().Language)); reason != "" {
+				recordSkip(ShowType, show.Show.IDs.TMDB, title, reason)
+				continue
+			}
+		}
+
+		if preview != nil {
+			*preview = append(*preview, SyncPreviewItem{TMDBID: show.Show.IDs.TMDB, Title: title})
+			continue
+		}
+
+		isNew := !uid.IsDuplicateShow(tmdbID)
+		written, _, _, err := writeShowStrm(show.Show.IDs.TMDB, false, false)
+		if err != nil {
 			continue
 		}
 
 		showIDs = append(showIDs, show.Show.IDs.TMDB)
+		titles[written.ID] = showItemTitle(written)
+		if isNew {
+			newShowIDs = append(newShowIDs, show.Show.IDs.TMDB)
+		}
+	}
+	if preview != nil {
+		return nil
 	}
 
 	// Cleanup unused map items
@@ -1178,16 +2815,58 @@ func SyncShowsList(listID string, updating bool, isUpdateNeeded bool) (err error
 	if err := updateBatchDBItem(showIDs, StateActive, ShowType, 0); err != nil {
 		return err
 	}
+	if err := setItemTitles(ShowType, titles); err != nil {
+		log.Warningf("Could not save titles of shows: %s", err)
+	}
+	cursor.done()
+	if err := setItemsOrigin(newShowIDs, listOrigin(user, listID), false); err != nil {
+		log.Warningf("Could not save origin of shows: %s", err)
+	}
+
+	// Removal relies on complete current list, so it is skipped if list was not fetched
+	// or some of shows were not resolved to TMDB
+	if currentErr == nil {
+		currentIDs := map[int]bool{}
+		isComplete := true
+		for _, show := range current {
+			if show == nil || show.Show == nil || show.Show.IDs.TMDB == 0 {
+				isComplete = false
+				break
+			}
+			currentIDs[show.Show.IDs.TMDB] = true
+		}
+		if isComplete {
+			removeDeletedListItems(ShowType, listOrigin(user, listID), currentIDs)
+		}
+	}
 
 	if !updating && len(showIDs) > 0 {
 		log.Noticef("Shows list (%s) added", listID)
-		if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && xbmc.DialogConfirmFocused("Elementum", fmt.Sprintf("LOCALIZE[30277];;%s", label))) {
-			xbmc.VideoLibraryScan()
+		if config.Get().LibraryUpdate == 0 || (config.Get().LibraryUpdate == 1 && confirmWithTimeout(fmt.Sprintf("LOCALIZE[30277];;%s", label))) {
+			for _, root := range ShowsLibraryPaths() {
+				RequestScan(root)
+			}
 		}
 	}
 	return nil
 }
 
+// hasRatingFilter checks if list imports are limited by rating or votes count
+func hasRatingFilter() bool {
+	return config.Get().LibraryMinRating > 0 || config.Get().LibraryMinVotes > 0
+}
+
+// passesRatingFilter checks TMDB vote data against configured thresholds, zero threshold is disabled
+func passesRatingFilter(voteAverage float32, voteCount int) bool {
+	if threshold := config.Get().LibraryMinRating; threshold > 0 && voteAverage < threshold {
+		return false
+	}
+	if threshold := config.Get().LibraryMinVotes; threshold > 0 && voteCount < threshold {
+		return false
+	}
+	return true
+}
+
 // DiffTraktShows ...
 func DiffTraktShows(previous, current []*trakt.Shows, isInitialized bool) []*trakt.Shows {
 	ret := make([]*trakt.Shows, 0, len(current))
@@ -1215,79 +2894,271 @@ func DiffTraktShows(previous, current []*trakt.Shows, isInitialized bool) []*tra
 //
 
 // AddMovie is adding movie to the library
-func AddMovie(tmdbID string, force bool) (*tmdb.Movie, error) {
+func AddMovie(tmdbID string, force bool, addedBy string) (*tmdb.Movie, error) {
+	movie, res, err := AddMovieEx(tmdbID, force, addedBy)
+	if err == nil && res.WasDuplicate {
+		notify(fmt.Sprintf("LOCALIZE[30287];;%s", movie.Title))
+		return nil, fmt.Errorf("Movie already added")
+	}
+
+	return movie, err
+}
+
+// AddMovieEx is adding movie to the library and reports written files,
+// non-empty addedBy is stored with the library item
+func AddMovieEx(tmdbID string, force bool, addedBy string) (*tmdb.Movie, *AddMovieResult, error) {
+	res := &AddMovieResult{Paths: []string{}}
 	if err := checkMoviesPath(); err != nil {
-		return nil, err
+		return nil, res, err
 	}
 
 	movie := tmdb.GetMovieByID(tmdbID, config.Get().Language)
 	if movie == nil {
-		return nil, fmt.Errorf("Movie with TMDB %s not found", tmdbID)
+		return nil, res, fmt.Errorf("Movie with TMDB %s not found", tmdbID)
 	}
 
 	if !force && uid.IsDuplicateMovie(tmdbID) {
-		xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30287];;%s", movie.Title), config.AddonIcon())
-		return nil, fmt.Errorf("Movie already added")
+		res.WasDuplicate = true
+		return movie, res, nil
 	}
 
-	if _, err := writeMovieStrm(tmdbID, force); err != nil {
-		return movie, err
+	written, writtenPaths, err := writeMovieStrm(tmdbID, force)
+	res.Paths = append(res.Paths, writtenPaths...)
+	if err != nil {
+		return movie, res, err
 	}
 
 	ID, _ := strconv.Atoi(tmdbID)
 	if err := updateDBItem(ID, StateActive, MovieType, 0); err != nil {
-		return movie, err
+		return movie, res, err
+	}
+	if err := setItemTitles(MovieType, map[int]ItemTitle{ID: movieItemTitle(written)}); err != nil {
+		log.Warningf("Could not save title of %s: %s", movie.Title, err)
+	}
+	if err := setItemsOrigin([]int{ID}, originManual, true); err != nil {
+		return movie, res, err
+	}
+	if addedBy != "" {
+		if err := setAddedBy(ID, addedBy); err != nil {
+			return movie, res, err
+		}
+
+		// NFO is written before the item is stored, so it should get the tag now
+		for _, p := range writtenPaths {
+			if strings.HasSuffix(p, ".nfo") {
+				writeMovieNFO(written, p)
+			}
+		}
 	}
 
 	log.Noticef("%s added to library", movie.Title)
-	return movie, nil
+	libraryChanged(LibraryEvent{Action: ActionAdded, MediaType: MovieType, TMDBID: ID, Paths: res.Paths})
+	return movie, res, nil
 }
 
 // AddShow is adding show to the library
-func AddShow(tmdbID string, force bool) (*tmdb.Show, error) {
+func AddShow(tmdbID string, force bool, addedBy string) (*tmdb.Show, error) {
+	show, res, err := AddShowEx(tmdbID, force, addedBy)
+	if err == nil && res.WasDuplicate {
+		notify(fmt.Sprintf("LOCALIZE[30287];;%s", show.Name))
+		return show, fmt.Errorf("Show already added")
+	}
+
+	return show, err
+}
+
+// AddShowEx is adding show to the library and reports written files,
+// non-empty addedBy is stored with the library item
+func AddShowEx(tmdbID string, force bool, addedBy string) (*tmdb.Show, *AddShowResult, error) {
+	res := &AddShowResult{Paths: []string{}}
 	if err := checkShowsPath(); err != nil {
-		return nil, err
+		return nil, res, err
 	}
 
 	ID, _ := strconv.Atoi(tmdbID)
 	show := tmdb.GetShowByID(tmdbID, config.Get().Language)
+	if show == nil {
+		return nil, res, fmt.Errorf("Show with TMDB %s not found", tmdbID)
+	}
 
 	if !force && uid.IsDuplicateShow(tmdbID) {
-		xbmc.Notify("Elementum", fmt.Sprintf("LOCALIZE[30287];;%s", show.Name), config.AddonIcon())
-		return show, fmt.Errorf("Show already added")
+		res.WasDuplicate = true
+		return show, res, nil
 	}
 
+	// Re-added show could have all episodes already written, so empty write is fine for it
+	showPath, showStrm := getShowPath(show)
+	isReAdd := force || len(episodeStrmFiles(showPath, showStrm)) > 0
+
+	var previous database.LibraryItem
+	hasPrevious := database.GetStormDB().One("ID", ID, &previous) == nil
+
 	if err := updateDBItem(ID, StateActive, ShowType, ID); err != nil {
-		return show, err
+		return show, res, err
+	}
+	if err := setItemsOrigin([]int{ID}, originManual, true); err != nil {
+		return show, res, err
+	}
+	if addedBy != "" {
+		if err := setAddedBy(ID, addedBy); err != nil {
+			return show, res, err
+		}
 	}
 
-	if _, err := writeShowStrm(ID, true, force); err != nil {
+	_, statErr := libFS.Stat(showPath)
+	createdFolder := os.IsNotExist(statErr)
+
+	_, written, _, err := writeShowStrm(ID, true, force)
+	res.Paths = append(res.Paths, written...)
+	for _, p := range written {
+		if strings.HasSuffix(p, ".strm") {
+			res.EpisodesWritten++
+		}
+	}
+	if err != nil {
 		log.Errorf("Error writing strm for a show: %s", err)
-		return show, err
+		return show, res, err
+	}
+
+	if res.EpisodesWritten == 0 && !isReAdd {
+		// Do not keep empty show as added
+		if hasPrevious {
+			database.GetStormDB().Save(&previous)
+		} else {
+			database.GetStormDB().DeleteStruct(&database.LibraryItem{ID: ID})
+		}
+
+		// Folder, created by this call, has only show artwork and nfo
+		if createdFolder {
+			if err := safeRemoveAll(showPath); err != nil {
+				log.Warningf("Could not remove empty show folder %s: %s", showPath, err)
+			}
+			removeFromManifest(filepath.Dir(showPath), ID, ShowType)
+			res.Paths = []string{}
+		}
+
+		log.Warningf("Nothing written for show %s (%d)", show.Name, ID)
+		return show, res, ErrNothingWritten
 	}
 
-	return show, nil
+	libraryChanged(LibraryEvent{Action: ActionAdded, MediaType: ShowType, TMDBID: ID, Paths: res.Paths})
+	return show, res, nil
 }
 
 func getShowPath(show *tmdb.Show) (showPath, showStrm string) {
 	// If this show already uses any directory - we should write there, to avoid having duplicates
-	paths := getShowPathsByTMDB(show.ID)
-	if len(paths) != 0 {
-		for path := range paths {
-			showPath = path
-			break
-		}
+	for path := range getShowPathsByTMDB(show.ID) {
+		return path, filepath.Base(path)
 	}
 
-	showName := show.OriginalName
-	if config.Get().StrmLanguage != config.Get().Language && show.Name != "" {
-		showName = show.Name
+	root := newTitleRoot(ShowsLibraryPaths())
+	showStrm = showFolderName(show)
+	showStrm = fitNameLogged(showStrm, showNameRoom(root))
+	showStrm = disambiguateFolder(root, showStrm, ShowType, show.ID)
+	showPath = filepath.Join(root, showStrm)
+
+	return
+}
+
+// showFolderName returns name of show folder, that is also a prefix of episode strm files
+func showFolderName(show *tmdb.Show) string {
+	name := util.ToFileName(titleWithYear(showTitle(show), getShowYear(show)))
+	if isJellyfinNaming() {
+		return name + jellyfinShowSuffix(show)
 	}
+	return withFolderID(name, show.ID)
+}
+
+// getShowYear returns year of the show first air date, empty for undated shows
+func getShowYear(show *tmdb.Show) string {
+	return strings.Split(show.FirstAirDate, "-")[0]
+}
 
-	showStrm = util.ToFileName(fmt.Sprintf("%s (%s)", showName, strings.Split(show.FirstAirDate, "-")[0]))
-	showPath = filepath.Join(ShowsLibraryPath(), showStrm)
+// titleWithYear returns "Title (Year)", or just the title if year is not known
+func titleWithYear(title, year string) string {
+	if year == "" {
+		return title
+	}
+	return fmt.Sprintf("%s (%s)", title, year)
+}
 
-	return
+// legacyEmptyYearName returns folder name, used for undated titles before empty year was dropped
+func legacyEmptyYearName(title string) string {
+	return util.ToFileName(fmt.Sprintf("%s ()", title))
+}
+
+// tmdbFolderSuffix returns TMDB ID suffix, recognized by Kodi in folder names
+func tmdbFolderSuffix(tmdbID int) string {
+	return fmt.Sprintf(" {tmdb-%d}", tmdbID)
+}
+
+// withFolderID appends TMDB ID suffix to folder name, if it is enabled,
+// Jellyfin naming always gets its own suffix
+func withFolderID(name string, tmdbID int) string {
+	if isJellyfinNaming() {
+		return name + jellyfinMovieSuffix(tmdbID)
+	} else if !config.Get().LibraryFolderUseID {
+		return name
+	}
+	return name + tmdbFolderSuffix(tmdbID)
+}
+
+// legacyMovieStrmTemplate is the naming of movie folders and strm files, used before templates
+const legacyMovieStrmTemplate = "{title} ({year})"
+
+// movieStrmName renders movie folder and strm file name from naming template,
+// year placeholder with its brackets and separators is dropped for undated movies
+func movieStrmName(template string, movie *tmdb.Movie, title, year string) string {
+	if year == "" {
+		template = emptyYearRegexp.ReplaceAllString(template, "")
+		template = repeatedSeparatorRegexp.ReplaceAllString(template, "$1")
+		template = strings.Trim(template, " -_.,")
+	}
+	return renderMovieStrmName(template, movie, title, year)
+}
+
+// renderMovieStrmName substitutes template placeholders as is
+func renderMovieStrmName(template string, movie *tmdb.Movie, title, year string) string {
+	imdbID := movie.IMDBId
+	if imdbID == "" && movie.ExternalIDs != nil {
+		imdbID = movie.ExternalIDs.IMDBId
+	}
+
+	return util.ToFileName(strings.NewReplacer(
+		"{title}", title,
+		"{year}", year,
+		"{tmdbid}", strconv.Itoa(movie.ID),
+		"{imdbid}", imdbID,
+	).Replace(template))
+}
+
+// getMovieYear returns release year of the movie in configured release region,
+// falling back to TMDB primary release date
+func getMovieYear(movie *tmdb.Movie) string {
+	year := strings.Split(movie.ReleaseDate, "-")[0]
+
+	region := config.Get().ReleaseRegion
+	if region == "" || movie.ReleaseDates == nil || movie.ReleaseDates.Results == nil {
+		return year
+	}
+
+	for _, r := range movie.ReleaseDates.Results {
+		if r.ReleaseDates == nil || strings.ToUpper(r.Iso3166_1) != region {
+			continue
+		}
+
+		earliest := ""
+		for _, d := range r.ReleaseDates {
+			if d.ReleaseDate != "" && (earliest == "" || d.ReleaseDate < earliest) {
+				earliest = d.ReleaseDate
+			}
+		}
+		if earliest != "" {
+			return strings.Split(earliest, "-")[0]
+		}
+	}
+
+	return year
 }
 
 func getMoviePathsByTMDB(id int) (ret map[string]bool) {
@@ -1295,7 +3166,12 @@ func getMoviePathsByTMDB(id int) (ret map[string]bool) {
 
 	if m, err := uid.GetMovieByTMDB(id); err == nil {
 		if m != nil && m.File != "" && strings.HasSuffix(m.File, ".strm") {
-			ret[filepath.Dir(m.File)] = true
+			if isMoviesLibraryPath(filepath.Dir(m.File)) {
+				// Flat library layout, movie has no own folder
+				ret[m.File] = true
+			} else {
+				ret[filepath.Dir(m.File)] = true
+			}
 		}
 	}
 
@@ -1308,7 +3184,7 @@ func getShowPathsByTMDB(id int) (ret map[string]bool) {
 	if s, err := uid.FindShowByTMDB(id); err == nil {
 		for _, e := range s.Episodes {
 			if e != nil && e.File != "" && strings.HasSuffix(e.File, ".strm") {
-				ret[filepath.Dir(e.File)] = true
+				ret[showPathOf(e.File)] = true
 			}
 		}
 	}
@@ -1317,39 +3193,234 @@ func getShowPathsByTMDB(id int) (ret map[string]bool) {
 }
 
 func getMoviePaths(movie *tmdb.Movie) map[string]bool {
-	paths := getMoviePathsByTMDB(movie.ID)
-	if len(paths) != 0 {
-		return paths
-	}
+	ret := getMoviePathsByTMDB(movie.ID)
 
+	// Folder could be written before release region was configured, so check primary year as well
 	titles := []string{movie.Title, movie.OriginalTitle}
-	for _, t := range titles {
-		movieStrm := util.ToFileName(fmt.Sprintf("%s (%s)", t, strings.Split(movie.ReleaseDate, "-")[0]))
-		moviePath := filepath.Join(MoviesLibraryPath(), movieStrm)
+	if alias, ok := config.Get().MovieTitleAliases[movie.ID]; ok {
+		titles = append([]string{alias}, titles...)
+	}
+	years := []string{getMovieYear(movie), strings.Split(movie.ReleaseDate, "-")[0]}
+
+	// Folders, written before naming template was changed, use default naming
+	templates := []string{config.Get().MovieStrmTemplate}
+	if templates[0] != legacyMovieStrmTemplate {
+		templates = append(templates, legacyMovieStrmTemplate)
+	}
+
+	suffixes := folderIDSuffixes(movie.ID)
+	movieNames := func(titles []string) []string {
+		names := []string{}
+		for _, tpl := range templates {
+			for _, t := range titles {
+				for _, y := range years {
+					name := movieStrmName(tpl, movie, t, y)
+					names = append(names, withIDSuffixes(name, suffixes)...)
+					if y == "" {
+						legacy := renderMovieStrmName(tpl, movie, t, y)
+						names = append(names, withIDSuffixes(legacy, suffixes)...)
+					}
+				}
+			}
+		}
+		return names
+	}
+	names := movieNames(titles)
+	// Folders, written under a title TMDB has renamed since, are named after one of alternative titles
+	akaNames := movieNames(movieAlternativeTitles(movie, titles))
+
+	// Folders, known to Kodi, are certainly of this movie, other folders with the same name
+	// could be of another title, so they are added only if they contain this very movie
+	known := len(ret) > 0
+	for _, root := range movieRoots(movie, titles) {
+		// Long names could be shortened to fit into path limit
+		for p := range findTitleFolders(root, withFittedNames(names, movieNameRoom(root, false)), MovieType, movie.ID) {
+			if !known || folderBelongsTo(p, MovieType, movie.ID) {
+				ret[p] = true
+			}
+		}
+		for p := range findTitleFolders(root, akaNames, MovieType, movie.ID) {
+			// Alternative titles are not unique, so folder should contain this very movie
+			if folderBelongsTo(p, MovieType, movie.ID) {
+				ret[p] = true
+			}
+		}
+	}
+	if config.Get().MovieLibraryFlat {
+		// Folders, written before flat layout was enabled, are kept in results
+		for _, root := range MoviesLibraryPaths() {
+			for _, name := range names {
+				p := filepath.Join(root, name+".strm")
+				if _, err := libFS.Stat(p); err == nil || len(existingMoviePartStrm(root, name)) > 0 {
+					ret[p] = true
+				}
+			}
+		}
+	}
+
+	return ret
+}
 
-		if _, err := os.Stat(moviePath); err == nil {
-			paths[moviePath] = true
+// movieGroupFolder returns name of grouping folder for the movie, empty if grouping is disabled.
+// Movies of a collection are grouped into collection folder, if it is enabled,
+// before grouping by letter or genre. Grouping is not used with flat library layout.
+func movieGroupFolder(movie *tmdb.Movie, title string) string {
+	if config.Get().MovieGroupBySet {
+		if c := getMovieCollection(movie); c != nil && c.Name != "" {
+			return util.ToFileName(c.Name)
+		}
+	}
+
+	switch config.Get().MovieFolderGrouping {
+	case MovieGroupingAlpha:
+		for _, r := range title {
+			if unicode.IsDigit(r) {
+				return "0-9"
+			} else if unicode.IsLetter(r) {
+				return string(unicode.ToUpper(r))
+			}
 		}
+		return "#"
+	case MovieGroupingGenre:
+		for _, g := range movie.Genres {
+			if g != nil && g.Name != "" {
+				return util.ToFileName(g.Name)
+			}
+		}
+		return "Other"
 	}
 
-	return paths
+	return ""
 }
 
-func getShowPaths(show *tmdb.Show) map[string]bool {
-	paths := getShowPathsByTMDB(show.ID)
-	if len(paths) != 0 {
-		return paths
+// movieRoots returns folders, that could contain folders of the movie: movies library folders,
+// grouping or collection folders of the movie titles, and folders, manifest lists the movie in
+func movieRoots(movie *tmdb.Movie, titles []string) []string {
+	ret := MoviesLibraryPaths()
+	seen := map[string]bool{}
+	for _, root := range ret {
+		seen[filepath.Clean(root)] = true
+	}
+	add := func(p string) {
+		if p = filepath.Clean(p); !seen[p] {
+			seen[p] = true
+			ret = append(ret, p)
+		}
+	}
+
+	for _, root := range MoviesLibraryPaths() {
+		for _, t := range titles {
+			if group := movieGroupFolder(movie, t); group != "" {
+				if _, err := libFS.Stat(filepath.Join(root, group)); err == nil {
+					add(filepath.Join(root, group))
+				}
+			}
+		}
+
+		// Grouping could be changed since the movie was written
+		if m, err := ReadManifest(root); err == nil {
+			for _, mi := range m.Items {
+				if mi != nil && mi.TMDBID == movie.ID && mi.MediaType == MovieType && mi.Path != "" {
+					add(filepath.Dir(filepath.Clean(mi.Path)))
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// moviePartStrmPath returns path of numbered part strm file of the movie
+func moviePartStrmPath(moviePath, movieStrm string, part int) string {
+	return filepath.Join(moviePath, fmt.Sprintf("%s-cd%d.strm", movieStrm, part))
+}
+
+// existingMoviePartStrm returns part strm files of the movie, that exist on disk
+func existingMoviePartStrm(moviePath, movieStrm string) []string {
+	ret := []string{}
+	for part := 1; ; part++ {
+		p := moviePartStrmPath(moviePath, movieStrm, part)
+		if _, err := libFS.Stat(p); err != nil {
+			return ret
+		}
+		ret = append(ret, p)
+	}
+}
+
+// isFlatMovieFile checks if movie path is a strm file of flat library layout, not a movie folder
+func isFlatMovieFile(path string) bool {
+	return strings.HasSuffix(path, ".strm") && isMoviesLibraryPath(filepath.Dir(path))
+}
+
+// flatMovieFiles returns existing files of the movie in flat library layout:
+// strm file or its parts and NFO and artwork files, sitting alongside
+func flatMovieFiles(strmPath string) []string {
+	base := strings.TrimSuffix(strmPath, ".strm")
+
+	ret := existingMoviePartStrm(filepath.Dir(strmPath), filepath.Base(base))
+	for _, p := range []string{strmPath, base + ".nfo", base + "-poster.jpg", base + "-fanart.jpg"} {
+		if _, err := libFS.Stat(p); err == nil {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// removeFlatMovieFiles removes movie files of flat library layout, or moves them to trash, if trash is enabled
+func removeFlatMovieFiles(strmPath string, tmdbID int) error {
+	if config.Get().LibraryTrashEnabled {
+		return moveFlatToTrash(strmPath, flatMovieFiles(strmPath), tmdbID)
 	}
 
+	for _, p := range flatMovieFiles(strmPath) {
+		if err := libFS.Remove(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getShowPaths(show *tmdb.Show) map[string]bool {
+	ret := getShowPathsByTMDB(show.ID)
+
 	titles := []string{show.Name, show.OriginalName}
-	for _, t := range titles {
-		showStrm := util.ToFileName(fmt.Sprintf("%s (%s)", t, strings.Split(show.FirstAirDate, "-")[0]))
-		showPath := filepath.Join(ShowsLibraryPath(), showStrm)
+	if alias, ok := config.Get().ShowTitleAliases[show.ID]; ok {
+		titles = append([]string{alias}, titles...)
+	}
+	year := getShowYear(show)
+	suffixes := folderIDSuffixes(show.ID, jellyfinShowSuffix(show))
+	showNames := func(titles []string) []string {
+		names := []string{}
+		for _, t := range titles {
+			name := util.ToFileName(titleWithYear(t, year))
+			names = append(names, withIDSuffixes(name, suffixes)...)
+			if year == "" {
+				names = append(names, withIDSuffixes(legacyEmptyYearName(t), suffixes)...)
+			}
+		}
+		return names
+	}
+
+	names := showNames(titles)
+	// Folders, written under a title TMDB has renamed since, are named after one of alternative titles
+	akaNames := showNames(showAlternativeTitles(show, titles))
 
-		if _, err := os.Stat(showPath); err == nil {
-			paths[showPath] = true
+	// Folders, known to Kodi, are certainly of this show, other folders with the same name
+	// could be of another title, so they are added only if they contain this very show
+	known := len(ret) > 0
+	for _, root := range ShowsLibraryPaths() {
+		// Long names could be shortened to fit into path limit
+		for p := range findTitleFolders(root, withFittedNames(names, showNameRoom(root)), ShowType, show.ID) {
+			if !known || folderBelongsTo(p, ShowType, show.ID) {
+				ret[p] = true
+			}
+		}
+		for p := range findTitleFolders(root, akaNames, ShowType, show.ID) {
+			// Alternative titles are not unique, so folder should contain this very show
+			if folderBelongsTo(p, ShowType, show.ID) {
+				ret[p] = true
+			}
 		}
 	}
 
-	return paths
+	return ret
 }
@@ -0,0 +1,59 @@
+package library
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/elgatito/elementum/tmdb"
+)
+
+func TestMovieStrmNameUndated(t *testing.T) {
+	setupMemLibrary(t)
+	movie := &tmdb.Movie{Entity: tmdb.Entity{ID: 42}, IMDBId: "tt0000042"}
+
+	tests := []struct {
+		template string
+		year     string
+		want     string
+	}{
+		{"{title} ({year})", "2020", "Title (2020)"},
+		{"{title} ({year})", "", "Title"},
+		{"{title} [{year}]", "", "Title"},
+		{"{title} - {year}", "", "Title"},
+		{"{year} - {title}", "", "Title"},
+		{"{title}.{year}", "", "Title"},
+		{"{title} - {year} - {imdbid}", "", "Title - tt0000042"},
+		{"{title} ({year}) {tmdbid}", "", "Title 42"},
+		{"{title} ({year}) - {imdbid}", "", "Title - tt0000042"},
+	}
+
+	for _, tt := range tests {
+		if got := movieStrmName(tt.template, movie, "Title", tt.year); got != tt.want {
+			t.Errorf("movieStrmName(%q, year %q) = %q, want %q", tt.template, tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestUndatedTitleFolders(t *testing.T) {
+	m := setupMemLibrary(t)
+
+	movie := &tmdb.Movie{Entity: tmdb.Entity{ID: 1, Title: "Unreleased", OriginalTitle: "Unreleased"}}
+	if year := getMovieYear(movie); year != "" {
+		t.Errorf("getMovieYear() = %q, want empty year", year)
+	}
+
+	show := &tmdb.Show{Entity: tmdb.Entity{ID: 2, OriginalName: "Undated Show"}}
+	if year := getShowYear(show); year != "" {
+		t.Errorf("getShowYear() = %q, want empty year", year)
+	}
+	if name := showFolderName(show); name != "Undated Show" {
+		t.Errorf("showFolderName() = %q, want %q", name, "Undated Show")
+	}
+
+	// Folders, written with empty brackets before, are still found for removal
+	legacy := filepath.Join(ShowsLibraryPaths()[0], legacyEmptyYearName("Undated Show"))
+	writeMemFiles(t, m, filepath.Join(legacy, "tvshow.nfo"))
+	if !getShowPaths(show)[legacy] {
+		t.Errorf("getShowPaths() does not find legacy folder %s", legacy)
+	}
+}
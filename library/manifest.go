@@ -0,0 +1,208 @@
+package library
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+)
+
+const manifestFileName = ".elementum-manifest.json"
+
+var (
+	manifestLock = sync.Mutex{}
+
+	// manifestBatches counts running syncs and updates, manifests, changed during them,
+	// are kept in memory and written once the last of them finishes
+	manifestBatches  int
+	pendingManifests = map[string]*Manifest{}
+)
+
+// beginManifestBatch starts keeping manifest changes in memory, returns function, that ends the batch
+func beginManifestBatch() func() {
+	manifestLock.Lock()
+	manifestBatches++
+	manifestLock.Unlock()
+
+	return endManifestBatch
+}
+
+// endManifestBatch writes manifests, changed during the batch, when no other batch is running
+func endManifestBatch() {
+	manifestLock.Lock()
+	defer manifestLock.Unlock()
+
+	if manifestBatches--; manifestBatches > 0 {
+		return
+	}
+
+	for root, m := range pendingManifests {
+		if err := writeManifest(root, m); err != nil {
+			log.Warningf("Could not write library manifest: %s", err)
+		}
+	}
+	pendingManifests = map[string]*Manifest{}
+}
+
+// ReadManifest reads manifest of a library root, including changes, not written yet
+func ReadManifest(root string) (*Manifest, error) {
+	manifestLock.Lock()
+	m, ok := pendingManifests[root]
+	manifestLock.Unlock()
+	if ok {
+		return m.copy(), nil
+	}
+
+	return readManifestFile(root)
+}
+
+// readManifestFile reads manifest file of a library root
+func readManifestFile(root string) (*Manifest, error) {
+	data, err := libFS.ReadFile(filepath.Join(root, manifestFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// manifestItemsByPath returns titles, listed in manifests of the roots, by their folder
+func manifestItemsByPath(roots []string) map[string]*ManifestItem {
+	ret := map[string]*ManifestItem{}
+	for _, root := range roots {
+		m, err := ReadManifest(root)
+		if err != nil {
+			continue
+		}
+		for _, mi := range m.Items {
+			if mi != nil && mi.Path != "" {
+				ret[filepath.Clean(mi.Path)] = mi
+			}
+		}
+	}
+	return ret
+}
+
+// updateManifest adds or replaces manifest item for the title
+func updateManifest(root string, item *ManifestItem) {
+	if !config.Get().LibraryManifest {
+		return
+	}
+
+	manifestLock.Lock()
+	defer manifestLock.Unlock()
+
+	m := loadManifest(root)
+	found := false
+	for i, mi := range m.Items {
+		if mi.TMDBID == item.TMDBID && mi.MediaType == item.MediaType {
+			m.Items[i] = item
+			found = true
+			break
+		}
+	}
+	if !found {
+		m.Items = append(m.Items, item)
+	}
+
+	saveManifest(root, m)
+}
+
+// removeFromManifest removes title from manifest
+func removeFromManifest(root string, tmdbID int, mediaType int) {
+	if !config.Get().LibraryManifest {
+		return
+	}
+
+	manifestLock.Lock()
+	defer manifestLock.Unlock()
+
+	m := loadManifest(root)
+	items := make([]*ManifestItem, 0, len(m.Items))
+	for _, mi := range m.Items {
+		if mi.TMDBID != tmdbID || mi.MediaType != mediaType {
+			items = append(items, mi)
+		}
+	}
+	if len(items) == len(m.Items) {
+		return
+	}
+	m.Items = items
+
+	saveManifest(root, m)
+}
+
+// removeManifest removes manifest file of a library root
+func removeManifest(root string) {
+	manifestLock.Lock()
+	defer manifestLock.Unlock()
+
+	delete(pendingManifests, root)
+	if err := libFS.Remove(filepath.Join(root, manifestFileName)); err != nil && !os.IsNotExist(err) {
+		log.Warningf("Could not remove library manifest: %s", err)
+	}
+}
+
+// loadManifest returns manifest of a library root for a change, should be called under manifestLock
+func loadManifest(root string) *Manifest {
+	if m, ok := pendingManifests[root]; ok {
+		return m
+	}
+	return readManifestOrEmpty(root)
+}
+
+// saveManifest writes changed manifest, or keeps it in memory while a batch is running,
+// should be called under manifestLock
+func saveManifest(root string, m *Manifest) {
+	if manifestBatches > 0 {
+		pendingManifests[root] = m
+		return
+	}
+
+	if err := writeManifest(root, m); err != nil {
+		log.Warningf("Could not write library manifest: %s", err)
+	}
+}
+
+// copy returns manifest with its own list of items, so it could be read while the original is changed
+func (m *Manifest) copy() *Manifest {
+	return &Manifest{UpdatedAt: m.UpdatedAt, Items: append([]*ManifestItem{}, m.Items...)}
+}
+
+func readManifestOrEmpty(root string) *Manifest {
+	m, err := readManifestFile(root)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warningf("Could not read library manifest, creating new one: %s", err)
+		}
+		return &Manifest{Items: []*ManifestItem{}}
+	}
+
+	return m
+}
+
+// writeManifest writes manifest into temporary file and then renames it,
+// so readers never see partially written manifest
+func writeManifest(root string, m *Manifest) error {
+	m.UpdatedAt = time.Now()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(root, manifestFileName)
+	if err := libFS.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+
+	return libFS.Rename(path+".tmp", path)
+}
@@ -0,0 +1,135 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/util"
+)
+
+const (
+	// NamingProfileKodi names folders and files the way Kodi scrapers expect,
+	// episodes are stored right in the show folder
+	NamingProfileKodi = iota
+	// NamingProfileJellyfin names folders with [tmdbid-N] and [tvdbid-N] tags,
+	// episodes are stored in "Season NN" folders
+	NamingProfileJellyfin
+)
+
+// isJellyfinNaming checks if library is written with Jellyfin naming profile
+func isJellyfinNaming() bool {
+	return config.Get().LibraryNamingProfile == NamingProfileJellyfin
+}
+
+// useSeasonFolders checks if episodes should be written into season folders,
+// either enabled in settings or required by naming profile
+func useSeasonFolders() bool {
+	return config.Get().ShowSeasonFolders || isJellyfinNaming()
+}
+
+// seasonFolderName returns name of season folder, specials go to "Season 00"
+func seasonFolderName(season int) string {
+	return fmt.Sprintf("Season %02d", season)
+}
+
+// isSeasonFolder checks if folder name looks like a season folder
+func isSeasonFolder(name string) bool {
+	var season int
+	n, _ := fmt.Sscanf(name, "Season %d", &season)
+	return n == 1 && name == seasonFolderName(season)
+}
+
+// episodeDir returns folder, where episode strm files of the season are written
+func episodeDir(showPath string, season int) string {
+	if !useSeasonFolders() {
+		return showPath
+	}
+	return filepath.Join(showPath, seasonFolderName(season))
+}
+
+// ensureEpisodeDir returns folder for episode strm files of the season, creating missing season folder
+func ensureEpisodeDir(showPath string, season int) (string, error) {
+	dir := episodeDir(showPath, season)
+	if _, err := libFS.Stat(dir); os.IsNotExist(err) {
+		if err := libFS.Mkdir(dir, 0755); err != nil {
+			return dir, err
+		}
+	}
+	return dir, nil
+}
+
+// removeEmptySeasonFolder removes season folder, that has no files left except season.nfo
+func removeEmptySeasonFolder(showPath string, season int) {
+	dir := filepath.Join(showPath, seasonFolderName(season))
+	entries, err := libFS.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.Name() != "season.nfo" {
+			return
+		}
+	}
+
+	if err := libFS.RemoveAll(dir); err != nil {
+		log.Warningf("Could not remove empty season folder %s: %s", dir, err)
+	}
+}
+
+// writeSeasonNFO writes season.nfo into season folder with season name and plot
+func writeSeasonNFO(season *tmdb.Season, dir string) (string, error) {
+	p := filepath.Join(dir, "season.nfo")
+	out := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<season>%s%s
+	<seasonnumber>%d</seasonnumber>%s
+</season>
+`, nfoTag("title", season.Name), nfoTag("plot", season.Overview), season.Season, nfoTag("premiered", season.AirDate))
+
+	if err := libFS.WriteFile(p, []byte(out), 0644); err != nil {
+		log.Errorf("Could not write NFO file: %s", err)
+		return p, err
+	}
+	return p, nil
+}
+
+// showPathOf returns show folder of the episode file, that could be stored in a season folder
+func showPathOf(episodePath string) string {
+	dir := filepath.Dir(episodePath)
+	if isSeasonFolder(filepath.Base(dir)) {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// jellyfinMovieSuffix returns TMDB ID tag, recognized by Jellyfin in movie names
+func jellyfinMovieSuffix(tmdbID int) string {
+	return fmt.Sprintf(" [tmdbid-%d]", tmdbID)
+}
+
+// jellyfinShowSuffix returns TVDB ID tag of the show, recognized by Jellyfin in folder names,
+// shows without TVDB ID get TMDB ID tag
+func jellyfinShowSuffix(show *tmdb.Show) string {
+	if show.ExternalIDs != nil {
+		if tvdbID := util.StrInterfaceToInt(show.ExternalIDs.TVDBID); tvdbID != 0 {
+			return fmt.Sprintf(" [tvdbid-%d]", tvdbID)
+		}
+	}
+	return jellyfinMovieSuffix(show.ID)
+}
+
+// folderIDSuffixes returns all ID suffixes, the title folder could be written with
+func folderIDSuffixes(tmdbID int, extra ...string) []string {
+	return append([]string{tmdbFolderSuffix(tmdbID), jellyfinMovieSuffix(tmdbID)}, extra...)
+}
+
+// withIDSuffixes returns folder name, followed by the name with each of ID suffixes
+func withIDSuffixes(name string, suffixes []string) []string {
+	ret := []string{name}
+	for _, suffix := range suffixes {
+		ret = append(ret, name+suffix)
+	}
+	return ret
+}
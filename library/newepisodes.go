@@ -0,0 +1,72 @@
+package library
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/elgatito/elementum/config"
+)
+
+var (
+	newEpisodesMu   sync.RWMutex
+	lastNewEpisodes []NewEpisode
+)
+
+// LastNewEpisodes returns episodes, added to the library by the last scheduled shows update
+func LastNewEpisodes() []NewEpisode {
+	newEpisodesMu.RLock()
+	defer newEpisodesMu.RUnlock()
+
+	return append([]NewEpisode{}, lastNewEpisodes...)
+}
+
+// NewEpisodesByShow groups new episodes by show, keeping order of episodes
+func NewEpisodesByShow(episodes []NewEpisode) map[int][]NewEpisode {
+	ret := map[int][]NewEpisode{}
+	for _, e := range episodes {
+		ret[e.ShowID] = append(ret[e.ShowID], e)
+	}
+	return ret
+}
+
+// notifyNewEpisodes shows single notification, summarizing new episodes of all shows.
+// Initial library population is not notified, as everything is new at that point.
+func notifyNewEpisodes(episodes []NewEpisode) {
+	if !config.Get().NotifyNewEpisodes || !initialized || len(episodes) == 0 {
+		return
+	}
+
+	notify(newEpisodesMessage(episodes))
+}
+
+// newEpisodesMessage returns summary of new episodes, shows are listed in order of appearance
+func newEpisodesMessage(episodes []NewEpisode) string {
+	byShow := NewEpisodesByShow(episodes)
+	if len(byShow) == 1 {
+		e := episodes[0]
+		if len(episodes) == 1 {
+			return fmt.Sprintf("LOCALIZE[30672];;%s;;%s", e.ShowName, episodeCode(e.Season, e.Episode))
+		}
+		return fmt.Sprintf("LOCALIZE[30673];;%d;;%s", len(episodes), e.ShowName)
+	}
+
+	shows := []string{}
+	seen := map[int]bool{}
+	for _, e := range episodes {
+		if seen[e.ShowID] {
+			continue
+		}
+		seen[e.ShowID] = true
+		shows = append(shows, fmt.Sprintf("%s (%d)", e.ShowName, len(byShow[e.ShowID])))
+	}
+
+	return fmt.Sprintf("LOCALIZE[30674];;%d;;%s", len(episodes), strings.Join(shows, ", "))
+}
+
+func setLastNewEpisodes(episodes []NewEpisode) {
+	newEpisodesMu.Lock()
+	defer newEpisodesMu.Unlock()
+
+	lastNewEpisodes = episodes
+}
@@ -0,0 +1,43 @@
+package library
+
+import (
+	"testing"
+
+	"github.com/elgatito/elementum/tmdb"
+)
+
+func TestMovieTrailerNFO(t *testing.T) {
+	trailers := func(sources ...string) *struct {
+		Youtube []*tmdb.Trailer `json:"youtube"`
+	} {
+		ret := &struct {
+			Youtube []*tmdb.Trailer `json:"youtube"`
+		}{}
+		for _, s := range sources {
+			ret.Youtube = append(ret.Youtube, &tmdb.Trailer{Source: s})
+		}
+		return ret
+	}
+
+	tests := []struct {
+		name  string
+		movie *tmdb.Movie
+		want  string
+	}{
+		{"no trailers", &tmdb.Movie{}, ""},
+		{"empty trailers", &tmdb.Movie{Trailers: trailers()}, ""},
+		{"empty source", &tmdb.Movie{Trailers: trailers("")}, ""},
+		{"first trailer", &tmdb.Movie{Trailers: trailers("abc", "def")}, "\n\t<trailer>plugin://plugin.video.youtube/play/?video_id=abc</trailer>"},
+		{"skips empty source", &tmdb.Movie{Trailers: trailers("", "def")}, "\n\t<trailer>plugin://plugin.video.youtube/play/?video_id=def</trailer>"},
+		{"full link", &tmdb.Movie{Trailers: trailers("https://www.youtube.com/watch?v=xyz")}, "\n\t<trailer>plugin://plugin.video.youtube/play/?video_id=xyz</trailer>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nfoTag("trailer", getMovieTrailer(tt.movie))
+			if got != tt.want {
+				t.Errorf("trailer tag = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/util"
+)
+
+const (
+	movieNFOTemplateName = "movie.nfo.tmpl"
+	showNFOTemplateName  = "tvshow.nfo.tmpl"
+)
+
+// defaultMovieNFOTemplate is used when there is no custom movie NFO template,
+// context is *tmdb.Movie
+const defaultMovieNFOTemplate = `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<movie>
+	<uniqueid type="unknown" default="false">{{.ID}}</uniqueid>
+	<uniqueid type="elementum" default="false">{{.ID}}</uniqueid>
+	<uniqueid type="tmdb" default="true">{{.ID}}</uniqueid>
+	<uniqueid type="imdb" default="false">{{.ExternalIDs.IMDBId}}</uniqueid>
+	<uniqueid type="tvdb" default="false">{{value .ExternalIDs.TVDBID}}</uniqueid>{{extra}}
+</movie>
+https://www.themoviedb.org/movie/{{.ID}}
+{{with .ExternalIDs.IMDBId}}https://www.imdb.com/title/{{.}}/
+{{end}}`
+
+// defaultShowNFOTemplate is used when there is no custom show NFO template,
+// context is *tmdb.Show
+const defaultShowNFOTemplate = `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<tvshow>
+	<uniqueid type="unknown" default="false">{{.ID}}</uniqueid>
+	<uniqueid type="elementum" default="false">{{.ID}}</uniqueid>
+	<uniqueid type="tmdb" default="true">{{.ID}}</uniqueid>
+	<uniqueid type="imdb" default="false">{{.ExternalIDs.IMDBId}}</uniqueid>
+	<uniqueid type="tvdb" default="false">{{value .ExternalIDs.TVDBID}}</uniqueid>{{extra}}
+</tvshow>
+https://www.themoviedb.org/tv/{{.ID}}
+{{with .ExternalIDs.IMDBId}}https://www.imdb.com/title/{{.}}/
+{{end}}{{with tvdb .ExternalIDs.TVDBID}}https://www.thetvdb.com/?tab=series&id={{.}}&lid=7
+{{end}}`
+
+// NFOTemplatesPath returns folder with custom NFO templates, that override default NFO markup
+func NFOTemplatesPath() string {
+	return filepath.Join(config.Get().ProfilePath, "nfo")
+}
+
+// renderNFO renders NFO with custom template from the config folder, if it exists,
+// or with default template. Extra contains generated metadata elements, available as {{extra}}.
+func renderNFO(name, defaultTemplate string, data interface{}, extra string) (string, error) {
+	funcs := template.FuncMap{
+		"extra": func() string { return extra },
+		"value": func(v interface{}) string { return fmt.Sprint(v) },
+		"tvdb":  util.StrInterfaceToInt,
+		"xml":   escapeXML,
+		"image": func(path string) string {
+			if path == "" {
+				return ""
+			}
+			return tmdb.ImageURL(path, "original")
+		},
+	}
+
+	custom := filepath.Join(NFOTemplatesPath(), name)
+	if content, err := ioutil.ReadFile(custom); err == nil {
+		out, err := executeNFOTemplate(name, string(content), funcs, data)
+		if err == nil {
+			return out, nil
+		}
+		log.Warningf("Could not render NFO template %s, using default one: %s", custom, err)
+	} else if !os.IsNotExist(err) {
+		log.Warningf("Could not read NFO template %s: %s", custom, err)
+	}
+
+	return executeNFOTemplate(name, defaultTemplate, funcs, data)
+}
+
+func executeNFOTemplate(name, text string, funcs template.FuncMap, data interface{}) (string, error) {
+	tpl, err := template.New(name).Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
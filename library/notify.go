@@ -0,0 +1,40 @@
+package library
+
+import (
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// isProfileAllowed checks if active Kodi profile should get library notifications and dialogs
+func isProfileAllowed() bool {
+	profiles := config.Get().NotifyProfiles
+	if len(profiles) == 0 {
+		return true
+	}
+
+	profile, err := xbmc.GetCurrentProfile()
+	if err != nil || profile == nil {
+		// Better to show a notification than to lose it
+		return true
+	}
+
+	for _, p := range profiles {
+		if strings.EqualFold(p, profile.Label) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notify shows library notification, if current Kodi profile is allowed to get it
+func notify(message string) {
+	if !isProfileAllowed() {
+		log.Infof("Notification suppressed for current Kodi profile: %s", message)
+		return
+	}
+
+	xbmc.Notify("Elementum", message, config.AddonIcon())
+}
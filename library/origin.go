@@ -0,0 +1,114 @@
+package library
+
+import (
+	"strconv"
+
+	"github.com/asdine/storm/q"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/trakt"
+)
+
+// originManual is origin of library items, added by the user
+const originManual = "manual"
+
+// listShrinkMinItems is a number of items, list could lose at once without any check
+const listShrinkMinItems = 5
+
+// listOrigin returns origin of library items, added by Trakt list sync,
+// lists of other users are keyed with their owner, so same list slugs of different users do not collide
+func listOrigin(user, listID string) string {
+	switch listID {
+	case "watchlist", "collection":
+		return listID
+	}
+
+	if owner, list := trakt.ParseListID(listID); owner != "" {
+		user, listID = owner, list
+	}
+	if user == "" || user == "id" || user == config.Get().TraktUsername {
+		return "list:" + listID
+	}
+	return "list:" + trakt.UserListPrefix + user + "/" + listID
+}
+
+// setItemsOrigin stores origin for library items, that do not have it yet,
+// so items, added manually or by another list, keep their origin.
+// With overwrite origin is replaced, so manual add takes the item away from the list.
+func setItemsOrigin(tmdbIDs []int, origin string, overwrite bool) error {
+	if len(tmdbIDs) == 0 {
+		return nil
+	}
+
+	tx, err := database.GetStormDB().Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range tmdbIDs {
+		li := getDBItem(tx, id)
+		if li.Origin == origin || (li.Origin != "" && !overwrite) {
+			continue
+		}
+
+		li.Origin = origin
+		if err := tx.Save(&li); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// removeDeletedListItems removes library items, added by the list, which are not in the list anymore.
+// Nothing is removed for empty list or for list, that lost most of its items at once,
+// since that is more likely a broken Trakt response, than user's edit.
+func removeDeletedListItems(mediaType int, origin string, current map[int]bool) {
+	if !config.Get().LibraryTraktRemoveDeleted {
+		return
+	} else if len(current) == 0 {
+		log.Warningf("List %s is empty, not removing its library items", origin)
+		return
+	}
+
+	lis, err := listLibraryItems(mediaType, StateActive, q.Eq("Origin", origin))
+	if err != nil {
+		log.Warningf("Could not get library items of %s: %s", origin, err)
+		return
+	}
+
+	deleted := []database.LibraryItem{}
+	for _, li := range lis {
+		if !current[li.ID] {
+			deleted = append(deleted, li)
+		}
+	}
+	if len(deleted) > listShrinkMinItems && len(deleted)*2 > len(lis) {
+		log.Warningf("List %s lost %d of %d library items at once, not removing them", origin, len(deleted), len(lis))
+		return
+	}
+
+	for _, li := range deleted {
+		if closer.IsSet() {
+			return
+		}
+
+		if mediaType == MovieType {
+			_, _, err = RemoveMovie(li.ID)
+		} else {
+			_, _, err = RemoveShow(strconv.Itoa(li.ID))
+		}
+		if err != nil {
+			log.Warningf("Could not remove %d, deleted from %s: %s", li.ID, origin, err)
+			continue
+		}
+
+		// Item was not removed by the user, so it should be written again, if it is back in the list
+		if err := database.GetStormDB().DeleteStruct(&database.LibraryItem{ID: li.ID}); err != nil {
+			log.Warningf("Could not forget library item %d: %s", li.ID, err)
+		}
+		log.Infof("Library item %d removed, since it was deleted from %s", li.ID, origin)
+	}
+}
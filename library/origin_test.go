@@ -0,0 +1,35 @@
+package library
+
+import (
+	"testing"
+
+	"github.com/elgatito/elementum/config"
+)
+
+func TestListOrigin(t *testing.T) {
+	conf := config.Get()
+	saved := conf.TraktUsername
+	conf.TraktUsername = "me"
+	defer func() { conf.TraktUsername = saved }()
+
+	tests := []struct {
+		user   string
+		listID string
+		want   string
+	}{
+		{"", "watchlist", "watchlist"},
+		{"", "collection", "collection"},
+		{"", "12345", "list:12345"},
+		{"me", "favorites", "list:favorites"},
+		{"bob", "favorites", "list:user:bob/favorites"},
+		{"", "user:bob/favorites", "list:user:bob/favorites"},
+		{"", "user:alice/favorites", "list:user:alice/favorites"},
+		{"", "user:me/favorites", "list:favorites"},
+	}
+
+	for _, tt := range tests {
+		if got := listOrigin(tt.user, tt.listID); got != tt.want {
+			t.Errorf("listOrigin(%q, %q) = %q, want %q", tt.user, tt.listID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package library
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PlayLink describes library item, referenced by the strm file
+type PlayLink struct {
+	MediaType int
+	TMDBID    int
+	Season    int
+	Episode   int
+	Quality   string
+}
+
+// ResolvePlayLink parses play link from strm file into library item reference,
+// query parameters, like quality hint, do not affect matched ids
+func ResolvePlayLink(link string) (*PlayLink, error) {
+	link = strings.TrimSpace(link)
+
+	var ret *PlayLink
+	if matches := showRegexp.FindStringSubmatch(link); len(matches) > 3 {
+		ret = &PlayLink{MediaType: EpisodeType}
+		ret.TMDBID, _ = strconv.Atoi(matches[1])
+		ret.Season, _ = strconv.Atoi(matches[2])
+		ret.Episode, _ = strconv.Atoi(matches[3])
+	} else if matches := movieRegexp.FindStringSubmatch(link); len(matches) > 1 {
+		ret = &PlayLink{MediaType: MovieType}
+		ret.TMDBID, _ = strconv.Atoi(matches[1])
+	} else {
+		return nil, errors.New("Not an Elementum play link")
+	}
+
+	if u, err := url.Parse(link); err == nil {
+		ret.Quality = u.Query().Get("quality")
+	}
+
+	return ret, nil
+}
+
+// FindOrphanedEpisodes walks show folders and returns episode strm files,
+// which belong to shows that are not active in the library
+func FindOrphanedEpisodes() ([]string, error) {
+	if err := checkShowsPath(); err != nil {
+		return nil, err
+	}
+
+	lis, err := ListLibraryItems(ShowType, StateActive)
+	if err != nil {
+		return nil, err
+	}
+
+	activeShows := map[int]bool{}
+	for _, li := range lis {
+		activeShows[li.ID] = true
+	}
+
+	ret := []string{}
+	files := []string{}
+	for _, root := range ShowsLibraryPaths() {
+		files = append(files, searchAllStrm(root)...)
+	}
+	for _, f := range files {
+		content, err := libFS.ReadFile(f)
+		if err != nil {
+			continue
+		}
+
+		link, err := ResolvePlayLink(string(content))
+		if err != nil || link.MediaType != EpisodeType {
+			continue
+		}
+
+		if !activeShows[link.TMDBID] {
+			ret = append(ret, f)
+		}
+	}
+
+	return ret, nil
+}
+
+// RemoveOrphanedEpisodes removes episode strm files, found by FindOrphanedEpisodes,
+// and show folders that became empty after that
+func RemoveOrphanedEpisodes(files []string) (removed []string, err error) {
+	dirs := map[string]bool{}
+	for _, f := range files {
+		if errRemove := removeEpisodeFile(f); errRemove != nil {
+			log.Warningf("Could not remove orphaned episode %s: %s", f, errRemove)
+			err = errRemove
+			continue
+		}
+
+		removed = append(removed, f)
+		dirs[filepath.Dir(f)] = true
+	}
+
+	for dir := range dirs {
+		if entries, errRead := libFS.ReadDir(dir); errRead == nil && len(entries) == 0 {
+			if errRemove := safeRemoveAll(dir); errRemove != nil {
+				log.Warningf("Could not remove empty directory %s: %s", dir, errRemove)
+			}
+		}
+	}
+
+	return
+}
+
+// RemoveOrphanedPaths removes episode strm files of shows, that are not active in the library,
+// with dryRun set files are only reported
+func RemoveOrphanedPaths(dryRun bool) ([]string, error) {
+	files, err := FindOrphanedEpisodes()
+	if err != nil || dryRun {
+		return files, err
+	}
+
+	return RemoveOrphanedEpisodes(files)
+}
+
+// searchAllStrm returns all strm files in the directory and its subdirectories
+func searchAllStrm(dir string) []string {
+	ret := []string{}
+
+	entries, err := libFS.ReadDir(dir)
+	if err != nil {
+		return ret
+	}
+
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
+		if e.IsDir() {
+			ret = append(ret, searchAllStrm(path)...)
+		} else if strings.HasSuffix(path, ".strm") {
+			ret = append(ret, path)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,113 @@
+package library
+
+import (
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// windowsMaxPath is a maximum path length, Windows API accepts without extended-length prefix
+	windowsMaxPath = 259
+	// sidecarReserve is a room for longest suffix of files, written next to strm file (nfo, artwork, parts)
+	sidecarReserve = 16
+	// episodeNameReserve is a room for " S00E000" code and extension of episode files
+	episodeNameReserve = 14
+)
+
+// yearSuffixRegexp matches year and ID suffixes at the end of title folder name
+var yearSuffixRegexp = regexp.MustCompile(`\s\(\d{4}\)(\s[\[{][^\]}]+[\]}])*$`)
+
+// maxPathLength returns path length limit of the platform, 0 means there is no limit
+func maxPathLength() int {
+	if runtime.GOOS == "windows" {
+		return windowsMaxPath
+	}
+	return 0
+}
+
+// pathLength returns number of characters in the path
+func pathLength(p string) int {
+	return utf8.RuneCountInString(p)
+}
+
+// fitName truncates title part of the name to at most length characters,
+// year and ID suffixes are preserved
+func fitName(name string, length int) string {
+	if length <= 0 || pathLength(name) <= length {
+		return name
+	}
+
+	suffix := ""
+	if loc := yearSuffixRegexp.FindStringIndex(name); loc != nil {
+		name, suffix = name[:loc[0]], name[loc[0]:]
+	}
+
+	keep := length - pathLength(suffix)
+	if keep < 1 {
+		keep = 1
+	}
+	if runes := []rune(name); len(runes) > keep {
+		name = string(runes[:keep])
+	}
+
+	// Windows drops trailing dots and spaces from file names
+	return strings.TrimRight(name, ". ") + suffix
+}
+
+// movieNameRoom returns maximum length of movie folder and strm file name, so written files
+// fit into path limit, in folder layout name is used both for the folder and for the file
+func movieNameRoom(root string, isFlat bool) int {
+	limit := maxPathLength()
+	if limit == 0 {
+		return 0
+	}
+
+	room := limit - pathLength(root) - 1 - sidecarReserve
+	if !isFlat {
+		room = (room - 1) / 2
+	}
+	return room
+}
+
+// showNameRoom returns maximum length of show folder name, that is also a prefix of episode files,
+// so episode files in season folders fit into path limit
+func showNameRoom(root string) int {
+	limit := maxPathLength()
+	if limit == 0 {
+		return 0
+	}
+
+	return (limit - pathLength(root) - 1 - pathLength(seasonFolderName(0)) - 1 - episodeNameReserve - sidecarReserve) / 2
+}
+
+// fitNameLogged shortens the name to fit into room and logs the change
+func fitNameLogged(name string, room int) string {
+	fitted := fitName(name, room)
+	if fitted != name {
+		log.Warningf("Name %s is too long for library path, shortened to %s", name, fitted)
+	}
+	return fitted
+}
+
+// withFittedNames adds shortened variants of names, that do not fit into room
+func withFittedNames(names []string, room int) []string {
+	if room <= 0 {
+		return names
+	}
+
+	ret := append([]string{}, names...)
+	for _, name := range names {
+		if fitted := fitName(name, room); fitted != name {
+			ret = append(ret, fitted)
+		}
+	}
+	return ret
+}
+
+// isPathTooLong checks if the path exceeds path limit of the platform
+func isPathTooLong(p string) bool {
+	limit := maxPathLength()
+	return limit > 0 && pathLength(p) > limit
+}
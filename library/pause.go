@@ -0,0 +1,45 @@
+package library
+
+import (
+	"sync/atomic"
+)
+
+// pausedTypes holds per media type pause flags for background refresh
+var pausedTypes [EpisodeType + 1]int32
+
+// pausedIndex maps seasons and episodes to their show, since they are refreshed together
+func pausedIndex(mediaType int) int {
+	if mediaType == SeasonType || mediaType == EpisodeType {
+		return ShowType
+	}
+	return mediaType
+}
+
+// PauseMediaType stops background refresh and sync of specific media type
+func PauseMediaType(mediaType int) {
+	if mediaType < MovieType || mediaType > EpisodeType {
+		return
+	}
+
+	atomic.StoreInt32(&pausedTypes[pausedIndex(mediaType)], 1)
+	log.Infof("Background updates paused for media type %d", pausedIndex(mediaType))
+}
+
+// ResumeMediaType resumes background refresh and sync of specific media type
+func ResumeMediaType(mediaType int) {
+	if mediaType < MovieType || mediaType > EpisodeType {
+		return
+	}
+
+	atomic.StoreInt32(&pausedTypes[pausedIndex(mediaType)], 0)
+	log.Infof("Background updates resumed for media type %d", pausedIndex(mediaType))
+}
+
+// IsMediaTypePaused checks whether background updates are paused for media type
+func IsMediaTypePaused(mediaType int) bool {
+	if mediaType < MovieType || mediaType > EpisodeType {
+		return false
+	}
+
+	return atomic.LoadInt32(&pausedTypes[pausedIndex(mediaType)]) == 1
+}
@@ -0,0 +1,62 @@
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// ExportMoviesM3U writes M3U playlist of active library movies,
+// with the same play links, that are written into strm files
+func ExportMoviesM3U(path string) error {
+	lis, err := ListLibraryItems(MovieType, StateActive)
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		name     string
+		duration int
+		link     string
+	}
+
+	entries := []entry{}
+	for _, li := range lis {
+		if closer.IsSet() {
+			return ErrLibraryClosing
+		}
+
+		movie := tmdb.GetMovie(li.ID, config.Get().StrmLanguage)
+		if movie == nil {
+			continue
+		}
+
+		duration := -1
+		if movie.Runtime > 0 {
+			duration = movie.Runtime * 60
+		}
+		entries = append(entries, entry{
+			name:     titleWithYear(movieTitle(movie), getMovieYear(movie)),
+			duration: duration,
+			link:     moviePlayLink(movie.ID),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+
+	var b bytes.Buffer
+	b.WriteString("#EXTM3U\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "#EXTINF:%d,%s\n%s\n", e.duration, e.name, e.link)
+	}
+
+	if err := libFS.WriteFile(path, b.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	log.Infof("Exported %d movies to playlist %s", len(entries), path)
+	return nil
+}
@@ -0,0 +1,54 @@
+package library
+
+import (
+	"sync"
+)
+
+const (
+	// ProgressUpdateShows reports scheduled update of library shows
+	ProgressUpdateShows = "update_shows"
+	// ProgressSyncMovies reports Trakt movies list sync
+	ProgressSyncMovies = "sync_movies"
+	// ProgressSyncShows reports Trakt shows list sync
+	ProgressSyncShows = "sync_shows"
+)
+
+// Progress describes state of a long running library operation
+type Progress struct {
+	Operation string `json:"operation"`
+	MediaType int    `json:"media_type"`
+	ListID    string `json:"list_id,omitempty"`
+	Current   int    `json:"current"`
+	Total     int    `json:"total"`
+}
+
+// ProgressFunc receives progress of library operations, it is called synchronously,
+// so it should return fast
+type ProgressFunc func(Progress)
+
+var (
+	progressMu      sync.RWMutex
+	progressHandler ProgressFunc
+)
+
+// SetProgressHandler replaces progress handler of library operations, returns previous one,
+// nil handler disables reporting
+func SetProgressHandler(f ProgressFunc) ProgressFunc {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+
+	previous := progressHandler
+	progressHandler = f
+	return previous
+}
+
+// reportProgress passes progress to the handler, if it is set
+func reportProgress(p Progress) {
+	progressMu.RLock()
+	f := progressHandler
+	progressMu.RUnlock()
+
+	if f != nil {
+		f(p)
+	}
+}
@@ -0,0 +1,89 @@
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// ShowEpisodeRanges scans folders of active library shows and returns
+// written episode ranges per season, sorted by show title
+func ShowEpisodeRanges() ([]ShowRange, error) {
+	lis, err := ListLibraryItems(ShowType, StateActive)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []ShowRange{}
+	for _, li := range lis {
+		if li.ID == 0 {
+			continue
+		}
+
+		show := tmdb.GetShow(li.ID, config.Get().StrmLanguage)
+		if show == nil {
+			continue
+		}
+
+		for path := range getShowPaths(show) {
+			r := ShowRange{
+				TMDBID:  li.ID,
+				Title:   show.Name,
+				Path:    path,
+				Seasons: []SeasonRange{},
+			}
+
+			seasons := map[int]*SeasonRange{}
+			for code := range episodeStrmFiles(path, filepath.Base(path)) {
+				var season, episode int
+				if n, _ := fmt.Sscanf(code, "S%dE%d", &season, &episode); n != 2 {
+					continue
+				}
+
+				sr, ok := seasons[season]
+				if !ok {
+					sr = &SeasonRange{Season: season, First: episode, Last: episode}
+					seasons[season] = sr
+				}
+				if episode < sr.First {
+					sr.First = episode
+				}
+				if episode > sr.Last {
+					sr.Last = episode
+				}
+				sr.Episodes++
+				r.Episodes++
+			}
+
+			for _, sr := range seasons {
+				r.Seasons = append(r.Seasons, *sr)
+			}
+			sort.Slice(r.Seasons, func(i, j int) bool { return r.Seasons[i].Season < r.Seasons[j].Season })
+
+			ret = append(ret, r)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Title == ret[j].Title {
+			return ret[i].Path < ret[j].Path
+		}
+		return ret[i].Title < ret[j].Title
+	})
+
+	return ret, nil
+}
+
+// String returns compact range, like "Breaking Bad: S01E01-S05E16 (62 episodes)"
+func (r ShowRange) String() string {
+	if len(r.Seasons) == 0 {
+		return fmt.Sprintf("%s: no episodes", r.Title)
+	}
+
+	first := r.Seasons[0]
+	last := r.Seasons[len(r.Seasons)-1]
+	return fmt.Sprintf("%s: S%02dE%02d-S%02dE%02d (%d episodes)", r.Title, first.Season, first.First, last.Season, last.Last, r.Episodes)
+}
@@ -0,0 +1,207 @@
+package library
+
+import (
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// ReconcileLibrary cross-checks library database with strm files on disk.
+// Orphan folders have Elementum strm files of titles, unknown to the database,
+// ghost entries are active titles without any strm files.
+// With fix set, orphans are added to the database, strm files of ghosts are recreated,
+// and ghosts, that TMDB does not know anymore, are removed from the database.
+func ReconcileLibrary(fix bool) (ReconcileReport, error) {
+	report := ReconcileReport{
+		OrphanFolders: []string{},
+		GhostMovies:   []int{},
+		GhostShows:    []int{},
+	}
+
+	if err := checkLibraryPath(); err != nil {
+		return report, err
+	}
+
+	started := time.Now()
+
+	var lis []database.LibraryItem
+	if err := database.GetStormDB().Select(q.Or(q.Eq("MediaType", MovieType), q.Eq("MediaType", ShowType))).Find(&lis); err != nil && err != storm.ErrNotFound {
+		return report, err
+	}
+
+	known := map[int]map[int]bool{
+		MovieType: {},
+		ShowType:  {},
+	}
+	for _, li := range lis {
+		known[li.MediaType][li.ID] = true
+	}
+
+	// Collect title folders of Elementum strm files, per library item
+	onDisk := map[int]map[int]map[string]bool{
+		MovieType: {},
+		ShowType:  {},
+	}
+	for _, root := range append(MoviesLibraryPaths(), ShowsLibraryPaths()...) {
+		for _, f := range searchAllStrm(root) {
+			if closer.IsSet() {
+				return report, ErrLibraryClosing
+			}
+
+			content, err := libFS.ReadFile(f)
+			if err != nil {
+				continue
+			}
+			link, err := ResolvePlayLink(string(content))
+			if err != nil {
+				continue
+			}
+
+			mediaType := link.MediaType
+			if mediaType == EpisodeType {
+				mediaType = ShowType
+			}
+
+			folder := filepath.Dir(f)
+			if mediaType == ShowType {
+				folder = showPathOf(f)
+			} else if isMoviesLibraryPath(folder) {
+				// Flat library layout, movie has no own folder
+				folder = f
+			}
+			if onDisk[mediaType][link.TMDBID] == nil {
+				onDisk[mediaType][link.TMDBID] = map[string]bool{}
+			}
+			onDisk[mediaType][link.TMDBID][folder] = true
+		}
+	}
+
+	for mediaType, titles := range onDisk {
+		for tmdbID, folders := range titles {
+			if known[mediaType][tmdbID] {
+				continue
+			}
+			for folder := range folders {
+				report.OrphanFolders = append(report.OrphanFolders, folder)
+			}
+			if fix && adoptOrphan(tmdbID, mediaType) {
+				report.Adopted++
+			}
+		}
+	}
+
+	for _, li := range lis {
+		if li.State != StateActive || li.Frozen || len(onDisk[li.MediaType][li.ID]) > 0 {
+			continue
+		}
+
+		if li.MediaType == MovieType {
+			report.GhostMovies = append(report.GhostMovies, li.ID)
+		} else {
+			report.GhostShows = append(report.GhostShows, li.ID)
+		}
+		if !fix {
+			continue
+		}
+
+		if closer.IsSet() {
+			return report, ErrLibraryClosing
+		}
+		recreated, removed := fixGhost(li.ID, li.MediaType)
+		if recreated {
+			report.Recreated++
+		} else if removed {
+			report.RemovedRows++
+		}
+	}
+
+	if report.Adopted > 0 || report.Recreated > 0 {
+		PlanKodiUpdate()
+	}
+
+	log.Infof("Library reconcile finished in %s: %d orphan folders, %d ghost movies, %d ghost shows, %d adopted, %d recreated, %d rows removed",
+		time.Since(started), len(report.OrphanFolders), len(report.GhostMovies), len(report.GhostShows), report.Adopted, report.Recreated, report.RemovedRows)
+
+	return report, nil
+}
+
+// adoptOrphan stores title, found on disk only, as an active library item
+func adoptOrphan(tmdbID, mediaType int) bool {
+	showID := 0
+	if mediaType == ShowType {
+		showID = tmdbID
+	}
+
+	if err := updateDBItem(tmdbID, StateActive, mediaType, showID); err != nil {
+		log.Warningf("Could not add orphan title %d to the library: %s", tmdbID, err)
+		return false
+	}
+	if err := setItemsOrigin([]int{tmdbID}, originManual, true); err != nil {
+		log.Warningf("Could not set origin of orphan title %d: %s", tmdbID, err)
+	}
+
+	log.Infof("Orphan title %d is added to the library", tmdbID)
+	return true
+}
+
+// fixGhost writes strm files of active title, that has none on disk,
+// title, TMDB answers "not found" for, is removed from the database instead
+func fixGhost(tmdbID, mediaType int) (recreated, removed bool) {
+	var written []string
+	var err error
+	var found bool
+	if mediaType == MovieType {
+		var movie *tmdb.Movie
+		movie, written, err = writeMovieStrm(strconv.Itoa(tmdbID), false)
+		found = movie != nil
+	} else {
+		var show *tmdb.Show
+		show, written, _, err = writeShowStrm(tmdbID, false, false)
+		found = show != nil
+	}
+
+	if err == nil && len(written) > 0 {
+		log.Infof("Recreated strm files of library title %d", tmdbID)
+		return true, false
+	}
+	if found {
+		log.Warningf("Could not recreate strm files of library title %d: %v", tmdbID, err)
+		return false, false
+	}
+
+	// TMDB could be unavailable, so title is only reported, unless TMDB does not know it
+	kind := "movie"
+	if mediaType == ShowType {
+		kind = "tv"
+	}
+	if !tmdb.IsNotFound(kind, tmdbID) {
+		log.Warningf("Could not get library title %d from TMDB, keeping it in the database: %v", tmdbID, err)
+		return false, false
+	}
+
+	if err := removeGhostRows(tmdbID, mediaType); err != nil {
+		log.Warningf("Could not remove library title %d from the database: %s", tmdbID, err)
+		return false, false
+	}
+
+	log.Infof("Library title %d, unknown to TMDB, is removed from the database", tmdbID)
+	return false, true
+}
+
+// removeGhostRows deletes title from the database, including episodes of a show
+func removeGhostRows(tmdbID, mediaType int) error {
+	db := database.GetStormDB()
+	if mediaType == ShowType {
+		if err := db.Select(q.Eq("MediaType", EpisodeType), q.Eq("ShowID", tmdbID)).Delete(&database.LibraryItem{}); err != nil && err != storm.ErrNotFound {
+			return err
+		}
+	}
+
+	return db.DeleteStruct(&database.LibraryItem{ID: tmdbID})
+}
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -13,7 +12,6 @@ import (
 	"time"
 
 	"github.com/cespare/xxhash"
-	"github.com/karrick/godirwalk"
 
 	"github.com/elgatito/elementum/cache"
 	"github.com/elgatito/elementum/config"
@@ -26,12 +24,14 @@ import (
 )
 
 var (
-	movieRegexp = regexp.MustCompile(`^plugin://plugin.video.elementum.*/movie/\w+/(\d+)`)
-	showRegexp  = regexp.MustCompile(`^plugin://plugin.video.elementum.*/show/\w+/(\d+)/(\d+)/(\d+)`)
+	movieRegexp = regexp.MustCompile(`^(?:plugin://plugin.video.elementum.*/movie/\w+/|https?://[^/]+/library/movie/play/)(\d+)`)
+	showRegexp  = regexp.MustCompile(`^(?:plugin://plugin.video.elementum.*/show/\w+/|https?://[^/]+/library/show/play/)(\d+)/(\d+)/(\d+)`)
 )
 
 // RefreshOnScan is launched when scan is finished
 func RefreshOnScan() error {
+	markScanFinished()
+
 	l := uid.Get()
 	l.Pending.IsOverall = true
 	l.Running.IsKodi = false
@@ -77,6 +77,8 @@ func Refresh() error {
 		log.Debugf("RefreshShows got an error: %v", err)
 	}
 
+	restorePreservedState()
+
 	log.Debugf("Library refresh finished in %s", time.Since(now))
 	return nil
 }
@@ -84,7 +86,7 @@ func Refresh() error {
 // RefreshMovies updates movies in the library
 func RefreshMovies() error {
 	l := uid.Get()
-	if l.Running.IsMovies || l.Running.IsKodi || !config.Get().LibraryEnabled || !config.Get().LibrarySyncEnabled || (!config.Get().LibrarySyncPlaybackEnabled && xbmc.PlayerIsPlaying()) {
+	if l.Running.IsMovies || l.Running.IsKodi || !config.Get().LibraryEnabled || !config.Get().LibrarySyncEnabled || (!config.Get().LibrarySyncPlaybackEnabled && xbmc.PlayerIsPlaying()) || IsMediaTypePaused(MovieType) {
 		return nil
 	}
 
@@ -149,7 +151,7 @@ func RefreshMovies() error {
 // RefreshShows updates shows in the library
 func RefreshShows() error {
 	l := uid.Get()
-	if l.Running.IsShows || l.Running.IsKodi || !config.Get().LibraryEnabled || !config.Get().LibrarySyncEnabled || (!config.Get().LibrarySyncPlaybackEnabled && xbmc.PlayerIsPlaying()) {
+	if l.Running.IsShows || l.Running.IsKodi || !config.Get().LibraryEnabled || !config.Get().LibrarySyncEnabled || (!config.Get().LibrarySyncPlaybackEnabled && xbmc.PlayerIsPlaying()) || IsMediaTypePaused(ShowType) {
 		return nil
 	}
 
@@ -308,7 +310,10 @@ func RefreshSeasons() error {
 // RefreshEpisodes updates episodes list for selected show in the library
 func RefreshEpisodes() error {
 	l := uid.Get()
-	if !l.Running.IsShows && len(pendingShows) == 0 {
+	lock.Lock()
+	hasPending := len(pendingShows) != 0
+	lock.Unlock()
+	if !l.Running.IsShows && !hasPending {
 		return nil
 	}
 
@@ -322,16 +327,18 @@ func RefreshEpisodes() error {
 	started := time.Now()
 
 	var shows []int
-	if len(pendingShows) != 0 {
+	if hasPending {
 		defer func() {
 			RefreshUIDs()
 		}()
 
+		lock.Lock()
 		shows = make([]int, 0, len(pendingShows))
 		for i := range pendingShows {
 			shows = append(shows, i)
 		}
 		pendingShows = map[int]bool{}
+		lock.Unlock()
 	} else {
 		l.Mu.Shows.Lock()
 		shows = make([]int, 0, len(l.Shows))
@@ -702,11 +709,11 @@ func findTMDBInFile(fileName string, pattern string) (id int, err error) {
 		cacheStore.Set(cacheKey, id, cache.LibraryResolveFileExpire)
 	}()
 
-	if _, errStat := os.Stat(fileName); errStat != nil {
+	if _, errStat := libFS.Stat(fileName); errStat != nil {
 		return 0, errStat
 	}
 
-	fileContent, errRead := ioutil.ReadFile(fileName)
+	fileContent, errRead := libFS.ReadFile(fileName)
 	if errRead != nil {
 		return 0, errRead
 	}
@@ -848,24 +855,24 @@ func refreshLocalMovies() {
 		return
 	}
 
-	moviesLibraryPath := MoviesLibraryPath()
-	if _, err := os.Stat(moviesLibraryPath); err != nil {
-		return
-	}
-
+	// Movies could be stored in any of library paths, missing ones are skipped
 	begin := time.Now()
-	addon := []byte(config.Get().Info.ID)
-	files := searchStrm(moviesLibraryPath)
+	files := []string{}
+	for _, root := range MoviesLibraryPaths() {
+		if _, err := libFS.Stat(root); err == nil {
+			files = append(files, searchStrm(root)...)
+		}
+	}
 	IDs := []int{}
 	for _, f := range files {
-		fileContent, err := ioutil.ReadFile(f)
-		if err != nil || len(fileContent) == 0 || bytes.Index(fileContent, addon) < 0 {
+		// Play links are matched both in plugin and HTTP modes of strm files
+		fileContent, err := libFS.ReadFile(f)
+		if err != nil || len(fileContent) == 0 {
 			continue
 		}
 
-		if matches := movieRegexp.FindSubmatch(fileContent); len(matches) > 1 {
-			id, _ := strconv.Atoi(string(matches[1]))
-			IDs = append(IDs, id)
+		if link, err := ResolvePlayLink(string(fileContent)); err == nil && link.MediaType == MovieType {
+			IDs = append(IDs, link.TMDBID)
 		}
 	}
 
@@ -883,26 +890,25 @@ func refreshLocalShows() {
 		return
 	}
 
-	showsLibraryPath := ShowsLibraryPath()
-	if _, err := os.Stat(showsLibraryPath); err != nil {
-		return
-	}
-
+	// Shows could be stored in any of library paths, missing ones are skipped
 	begin := time.Now()
-	addon := []byte(config.Get().Info.ID)
-	files := searchStrm(showsLibraryPath)
+	files := []string{}
+	for _, root := range ShowsLibraryPaths() {
+		if _, err := libFS.Stat(root); err == nil {
+			files = append(files, searchStrm(root)...)
+		}
+	}
 	IDs := map[int]bool{}
 	for _, f := range files {
-		fileContent, err := ioutil.ReadFile(f)
-		if err != nil || len(fileContent) == 0 || bytes.Index(fileContent, addon) < 0 {
+		// Play links are matched both in plugin and HTTP modes of strm files
+		fileContent, err := libFS.ReadFile(f)
+		if err != nil || len(fileContent) == 0 {
 			continue
 		}
 
-		if matches := showRegexp.FindSubmatch(fileContent); len(matches) > 1 {
-			showID, _ := strconv.Atoi(string(matches[1]))
-
-			if !wasRemoved(showID, ShowType) {
-				IDs[showID] = true
+		if link, err := ResolvePlayLink(string(fileContent)); err == nil && link.MediaType == EpisodeType {
+			if !WasRemoved(link.TMDBID, ShowType) {
+				IDs[link.TMDBID] = true
 			}
 		}
 	}
@@ -920,31 +926,60 @@ func refreshLocalShows() {
 }
 
 func searchStrm(dir string) []string {
+	root, err := libFS.Stat(dir)
+	if err != nil {
+		return []string{}
+	}
+	return searchStrmIn(dir, []os.FileInfo{root})
+}
+
+// searchStrmIn returns strm files in dir and its subdirectories, parents are directories on the way
+// from the search root, so symbolic link, pointing back to one of them, does not loop forever
+func searchStrmIn(dir string, parents []os.FileInfo) []string {
 	ret := []string{}
-	foundDirs := []string{}
 
-	godirwalk.Walk(dir, &godirwalk.Options{
-		FollowSymbolicLinks: true,
-		Callback: func(osPathname string, de *godirwalk.Dirent) error {
-			if !strings.HasSuffix(osPathname, ".strm") {
-				return nil
-			}
+	entries, err := libFS.ReadDir(dir)
+	if err != nil {
+		return ret
+	}
+
+	// Make sure we return only one file per directory, no need to get all of them
+	found := false
+	for _, e := range entries {
+		path := filepath.Join(dir, e.Name())
 
-			// Make sure we return only one file per directory, no need to get all of them
-			dir := filepath.Dir(osPathname)
-			if util.StringSliceContains(foundDirs, dir) {
-				return nil
+		// Follow symbolic links, same as for regular directories
+		if e.Mode()&os.ModeSymlink != 0 {
+			if fi, err := libFS.Stat(path); err == nil {
+				if fi.IsDir() && isParentDir(fi, parents) {
+					log.Warningf("Skipping symbolic link %s, that loops back to its parent", path)
+					continue
+				}
+				e = fi
 			}
+		}
 
-			foundDirs = append(foundDirs, dir)
-			ret = append(ret, osPathname)
-			return nil
-		},
-	})
+		if e.IsDir() {
+			ret = append(ret, searchStrmIn(path, append(parents[:len(parents):len(parents)], e))...)
+		} else if !found && strings.HasSuffix(path, ".strm") {
+			found = true
+			ret = append(ret, path)
+		}
+	}
 
 	return ret
 }
 
+// isParentDir checks if directory is one of the parents
+func isParentDir(dir os.FileInfo, parents []os.FileInfo) bool {
+	for _, p := range parents {
+		if os.SameFile(dir, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // MarkKodiRefresh ...
 func MarkKodiRefresh() {
 	uid.Get().Running.IsKodi = true
@@ -992,3 +1027,31 @@ func PlanShowUpdate(showID int) {
 	lock.Unlock()
 	uid.Get().Pending.IsEpisodes = true
 }
+
+// ForceRefresh plans rescan of Kodi library for the media type, it is picked up by the library watcher,
+// once other library operations are finished. MovieType sets Pending.IsMovies,
+// ShowType, SeasonType and EpisodeType set Pending.IsShows, since episodes are refreshed with shows,
+// any other value (e.g. -1) sets Pending.IsOverall to refresh movies and shows together.
+func ForceRefresh(mediaType int) {
+	switch mediaType {
+	case MovieType:
+		PlanMoviesUpdate()
+	case ShowType, SeasonType, EpisodeType:
+		PlanShowsUpdate()
+	default:
+		PlanOverallUpdate()
+	}
+}
+
+// ForceRefreshNow runs rescan of Kodi library for the media type right away,
+// without waiting for other running library operations. Media types are mapped like in ForceRefresh.
+func ForceRefreshNow(mediaType int) error {
+	switch mediaType {
+	case MovieType:
+		return RefreshMovies()
+	case ShowType, SeasonType, EpisodeType:
+		return RefreshShows()
+	default:
+		return Refresh()
+	}
+}
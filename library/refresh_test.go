@@ -0,0 +1,65 @@
+package library
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSearchStrmSymlinkLoop(t *testing.T) {
+	previous := SetFileSystem(osFS{})
+	defer SetFileSystem(previous)
+
+	root, err := ioutil.TempDir("", "elementum-library")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	show := filepath.Join(root, "Show (2020)")
+	if err := os.MkdirAll(filepath.Join(show, "Season 01"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	strm := filepath.Join(show, "Season 01", "Show (2020) S01E01.strm")
+	if err := ioutil.WriteFile(strm, []byte("link"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(show, "Season 01", "loop")); err != nil {
+		t.Skipf("Symbolic links are not supported: %s", err)
+	}
+
+	if got, want := searchStrm(root), []string{strm}; !reflect.DeepEqual(got, want) {
+		t.Errorf("searchStrm() = %v, want %v", got, want)
+	}
+}
+
+func TestResolvePlayLinkHosts(t *testing.T) {
+	tests := []struct {
+		link      string
+		mediaType int
+		tmdbID    int
+	}{
+		{"plugin://plugin.video.elementum/library/movie/play/550", MovieType, 550},
+		{"http://127.0.0.1:65220/library/movie/play/550?quality=1080p", MovieType, 550},
+		{"plugin://plugin.video.elementum/library/show/play/1399/1/2", EpisodeType, 1399},
+		{"http://192.168.1.2:65220/library/show/play/1399/1/2", EpisodeType, 1399},
+		{"http://example.com/other/movie/play/550", -1, 0},
+		{"https://example.com/addon/show/play/1399/1/2", -1, 0},
+		{"plugin://plugin.video.other/library/movie/play/550", -1, 0},
+	}
+
+	for _, tt := range tests {
+		link, err := ResolvePlayLink(tt.link)
+		if tt.mediaType == -1 {
+			if err == nil {
+				t.Errorf("ResolvePlayLink(%s) = %+v, want error for foreign link", tt.link, link)
+			}
+			continue
+		}
+		if err != nil || link.MediaType != tt.mediaType || link.TMDBID != tt.tmdbID {
+			t.Errorf("ResolvePlayLink(%s) = %+v, %v, want type %d, ID %d", tt.link, link, err, tt.mediaType, tt.tmdbID)
+		}
+	}
+}
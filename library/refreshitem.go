@@ -0,0 +1,70 @@
+package library
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/elgatito/elementum/database"
+)
+
+// RefreshMovieByTMDB re-reads library movie from TMDB and rewrites its strm and NFO files,
+// then asks Kodi to scan the movie folder
+func RefreshMovieByTMDB(tmdbID int) error {
+	if err := checkMoviesPath(); err != nil {
+		return err
+	} else if !isActiveItem(tmdbID, MovieType) {
+		return fmt.Errorf("Movie %d is not in the library", tmdbID)
+	}
+
+	ClearTmdbCacheForID(tmdbID, MovieType)
+
+	movie, written, err := writeMovieStrm(strconv.Itoa(tmdbID), true)
+	if err != nil {
+		return err
+	}
+
+	for p := range getMoviePaths(movie) {
+		RequestScan(p)
+	}
+	PlanKodiUpdate()
+
+	log.Noticef("%s refreshed in library", movie.Title)
+	libraryChanged(LibraryEvent{Action: ActionUpdated, MediaType: MovieType, TMDBID: tmdbID, Paths: written})
+	return nil
+}
+
+// RefreshShowByTMDB re-reads library show from TMDB and rewrites its strm and NFO files,
+// so new seasons are written right away, then asks Kodi to scan the show folder
+func RefreshShowByTMDB(tmdbID int) error {
+	if err := checkShowsPath(); err != nil {
+		return err
+	} else if !isActiveItem(tmdbID, ShowType) {
+		return fmt.Errorf("Show %d is not in the library", tmdbID)
+	}
+
+	ClearTmdbCacheForID(tmdbID, ShowType)
+
+	show, written, _, err := writeShowStrm(tmdbID, false, true)
+	if err != nil {
+		return err
+	}
+
+	for p := range getShowPaths(show) {
+		RequestScan(p)
+	}
+	PlanKodiUpdate()
+
+	log.Noticef("%s refreshed in library", show.Name)
+	libraryChanged(LibraryEvent{Action: ActionUpdated, MediaType: ShowType, TMDBID: tmdbID, Paths: written})
+	return nil
+}
+
+// isActiveItem checks if title is stored in the library and not removed
+func isActiveItem(tmdbID, mediaType int) bool {
+	var li database.LibraryItem
+	if err := database.GetStormDB().One("ID", tmdbID, &li); err != nil {
+		return false
+	}
+
+	return li.MediaType == mediaType && li.State == StateActive
+}
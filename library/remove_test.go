@@ -0,0 +1,48 @@
+package library
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+func TestRemoveEpisodeFilesRenamedShow(t *testing.T) {
+	m := setupMemLibrary(t)
+	config.Get().ShowSeasonFolders = true
+	root := ShowsLibraryPaths()[0]
+
+	// Show was written before TMDB renamed it, old title is kept as an alternative one
+	oldPath := filepath.Join(root, "Old Title (2020)")
+	removedStrm := filepath.Join(oldPath, "Season 01", "Old Title (2020) S01E02.strm")
+	keptStrm := filepath.Join(oldPath, "Season 01", "Old Title (2020) S01E01.strm")
+	writeMemFiles(t, m, keptStrm, removedStrm)
+	for p, link := range map[string]string{
+		keptStrm:    "plugin://plugin.video.elementum/library/show/play/1/1/1",
+		removedStrm: "plugin://plugin.video.elementum/library/show/play/1/1/2",
+	} {
+		if err := m.WriteFile(p, []byte(link), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	show := &tmdb.Show{Entity: tmdb.Entity{ID: 1, Name: "New Title", OriginalName: "New Title", FirstAirDate: "2020-01-10"}}
+	show.AlternativeTitles = &struct {
+		Titles []*tmdb.AlternativeTitle `json:"results"`
+	}{Titles: []*tmdb.AlternativeTitle{{Title: "Old Title"}}}
+
+	if _, ok := getShowPaths(show)[oldPath]; !ok {
+		t.Fatalf("getShowPaths() does not find folder %s, written under old title", oldPath)
+	}
+
+	removed, err := removeEpisodeFiles(show, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != removedStrm {
+		t.Errorf("removeEpisodeFiles() = %v, want [%s]", removed, removedStrm)
+	}
+	assertMemFiles(t, m, false, removedStrm)
+	assertMemFiles(t, m, true, keptStrm)
+}
@@ -0,0 +1,129 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+const resolveWorkers = 5
+
+// ErrLibraryClosing is returned when long operation is interrupted by library shutdown
+var ErrLibraryClosing = errors.New("Library is closing")
+
+// resolveSem limits concurrent TMDB lookups of all batch resolves together,
+// so warming the cache and syncing lists at once does not flood TMDB API
+var resolveSem = make(chan struct{}, resolveWorkers)
+
+// ExternalRef describes IMDB/TVDB ID of a movie or a show that should be resolved into TMDB ID
+type ExternalRef struct {
+	MediaType int
+	Source    string
+	ID        string
+}
+
+// Key returns map key under which resolved TMDB ID is returned
+func (r ExternalRef) Key() string {
+	return r.Source + ":" + r.ID
+}
+
+// BatchResolveExternalIDs resolves TMDB IDs for many external IDs concurrently,
+// results are kept in the resolve cache, unresolved items are not in the result
+func BatchResolveExternalIDs(items []ExternalRef) map[string]int {
+	ret, _ := resolveExternalIDs(items)
+	return ret
+}
+
+// WarmResolveCache resolves and caches TMDB IDs for external IDs up front,
+// so following list syncs or imports do not resolve them one by one
+func WarmResolveCache(refs []ExternalRef) error {
+	ret, err := resolveExternalIDs(refs)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Resolve cache warmed up with %d of %d external IDs", len(ret), len(refs))
+	return nil
+}
+
+// cachedExternalIDs returns TMDB IDs of external IDs, that are already in the resolve cache
+func cachedExternalIDs(refs []ExternalRef) map[string]int {
+	ret := map[string]int{}
+	cacheStore := cache.NewDBStore()
+	for _, ref := range refs {
+		id := 0
+		if err := cacheStore.Get(fmt.Sprintf(cache.LibraryResolveIDKey, ref.MediaType, ref.Source, ref.ID), &id); err == nil && id != 0 {
+			ret[ref.Key()] = id
+		}
+	}
+	return ret
+}
+
+func resolveExternalIDs(items []ExternalRef) (map[string]int, error) {
+	ret := map[string]int{}
+	if len(items) == 0 {
+		return ret, nil
+	}
+
+	cacheStore := cache.NewDBStore()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	seen := map[string]bool{}
+	for _, item := range items {
+		if closer.IsSet() {
+			wg.Wait()
+			return ret, ErrLibraryClosing
+		}
+		if item.ID == "" || item.ID == "0" || seen[item.Key()] {
+			continue
+		}
+		seen[item.Key()] = true
+
+		wg.Add(1)
+		resolveSem <- struct{}{}
+		go func(item ExternalRef) {
+			defer func() {
+				<-resolveSem
+				wg.Done()
+			}()
+
+			id := 0
+			key := fmt.Sprintf(cache.LibraryResolveIDKey, item.MediaType, item.Source, item.ID)
+			if err := cacheStore.Get(key, &id); err != nil || id == 0 {
+				id = resolveExternalID(item)
+				if id != 0 {
+					cacheStore.Set(key, id, cache.LibraryResolveIDExpire)
+				}
+			}
+
+			if id != 0 {
+				mu.Lock()
+				ret[item.Key()] = id
+				mu.Unlock()
+			}
+		}(item)
+	}
+	wg.Wait()
+
+	return ret, nil
+}
+
+func resolveExternalID(item ExternalRef) int {
+	r := tmdb.Find(item.ID, item.Source)
+	if r == nil {
+		return 0
+	}
+
+	if item.MediaType == MovieType && len(r.MovieResults) > 0 && r.MovieResults[0] != nil {
+		return r.MovieResults[0].ID
+	} else if item.MediaType == ShowType && len(r.TVResults) > 0 && r.TVResults[0] != nil {
+		return r.TVResults[0].ID
+	}
+
+	return 0
+}
@@ -0,0 +1,168 @@
+package library
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/library/uid"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// preservedStateExpire defines how long captured state waits for Kodi rescan
+const preservedStateExpire = 24 * time.Hour
+
+type preservedState struct {
+	Playcount  int
+	Position   float64
+	Total      float64
+	CapturedAt time.Time
+}
+
+var (
+	preservedMu       sync.Mutex
+	preservedMovies   = map[int]*preservedState{}
+	preservedEpisodes = map[preservedEpisodeKey]*preservedState{}
+
+	// lastScanFinished is the time of the latest finished Kodi scan, state, captured after it,
+	// still belongs to Kodi items of old files, so it waits for the next scan
+	lastScanFinished time.Time
+)
+
+// markScanFinished records finished Kodi scan, so state, captured before it, could be restored
+func markScanFinished() {
+	preservedMu.Lock()
+	lastScanFinished = time.Now()
+	preservedMu.Unlock()
+}
+
+type preservedEpisodeKey struct {
+	ShowID  int
+	Season  int
+	Episode int
+}
+
+func newPreservedState(uids *uid.UniqueIDs, resume *uid.Resume) *preservedState {
+	if uids == nil || (uids.Playcount == 0 && (resume == nil || resume.Position == 0)) {
+		return nil
+	}
+
+	ret := &preservedState{
+		Playcount:  uids.Playcount,
+		CapturedAt: time.Now(),
+	}
+	if resume != nil {
+		ret.Position = resume.Position
+		ret.Total = resume.Total
+	}
+
+	return ret
+}
+
+// preserveMovieState captures Kodi watched state of a movie before its strm is rewritten
+func preserveMovieState(tmdbID int) {
+	if !config.Get().PreserveResumeOnRewrite {
+		return
+	}
+
+	m, err := uid.GetMovieByTMDB(tmdbID)
+	if err != nil || m == nil {
+		return
+	}
+
+	if s := newPreservedState(m.UIDs, m.Resume); s != nil {
+		preservedMu.Lock()
+		preservedMovies[tmdbID] = s
+		preservedMu.Unlock()
+	}
+}
+
+// preserveShowState captures Kodi watched state of show episodes before strm files are rewritten
+func preserveShowState(tmdbID int) {
+	if !config.Get().PreserveResumeOnRewrite {
+		return
+	}
+
+	s, err := uid.GetShowByTMDB(tmdbID)
+	if err != nil || s == nil {
+		return
+	}
+
+	preservedMu.Lock()
+	defer preservedMu.Unlock()
+
+	for _, e := range s.Episodes {
+		if e == nil {
+			continue
+		}
+		if state := newPreservedState(e.UIDs, e.Resume); state != nil {
+			preservedEpisodes[preservedEpisodeKey{tmdbID, e.Season, e.Episode}] = state
+		}
+	}
+}
+
+// restorePreservedState applies captured watched state to items,
+// re-created by Kodi after the rescan, matching them by TMDB uniqueid.
+// State, captured after the latest scan, is kept until the next one.
+func restorePreservedState() {
+	preservedMu.Lock()
+	defer preservedMu.Unlock()
+
+	if len(preservedMovies) == 0 && len(preservedEpisodes) == 0 {
+		return
+	}
+
+	for tmdbID, s := range preservedMovies {
+		if !s.CapturedAt.Before(lastScanFinished) {
+			if time.Since(s.CapturedAt) > preservedStateExpire {
+				delete(preservedMovies, tmdbID)
+			}
+			continue
+		}
+
+		m, err := uid.GetMovieByTMDB(tmdbID)
+		if err != nil || m == nil || m.UIDs == nil {
+			if time.Since(s.CapturedAt) > preservedStateExpire {
+				delete(preservedMovies, tmdbID)
+			}
+			continue
+		}
+
+		if m.UIDs.Playcount != s.Playcount || m.Resume == nil || m.Resume.Position != s.Position {
+			log.Debugf("Restoring watched state for movie %d: playcount=%d, position=%.0f", tmdbID, s.Playcount, s.Position)
+			xbmc.SetMovieWatched(m.UIDs.Kodi, s.Playcount, int(s.Position), int(s.Total))
+		}
+		delete(preservedMovies, tmdbID)
+	}
+
+	if len(preservedEpisodes) == 0 {
+		return
+	}
+
+	for key, s := range preservedEpisodes {
+		if !s.CapturedAt.Before(lastScanFinished) {
+			if time.Since(s.CapturedAt) > preservedStateExpire {
+				delete(preservedEpisodes, key)
+			}
+			continue
+		}
+
+		show, err := uid.GetShowByTMDB(key.ShowID)
+		var e *uid.Episode
+		if err == nil && show != nil {
+			e = show.GetEpisode(key.Season, key.Episode)
+		}
+		if e == nil || e.UIDs == nil {
+			if time.Since(s.CapturedAt) > preservedStateExpire {
+				delete(preservedEpisodes, key)
+			}
+			continue
+		}
+
+		if e.UIDs.Playcount != s.Playcount || e.Resume == nil || e.Resume.Position != s.Position {
+			log.Debugf("Restoring watched state for episode %d S%02dE%02d: playcount=%d, position=%.0f", key.ShowID, key.Season, key.Episode, s.Playcount, s.Position)
+			xbmc.SetEpisodeWatched(e.UIDs.Kodi, s.Playcount, int(s.Position), int(s.Total))
+		}
+		delete(preservedEpisodes, key)
+	}
+}
@@ -0,0 +1,55 @@
+package library
+
+import (
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+)
+
+// PurgeDeletedItems removes library items, that stay deleted longer than retention period,
+// returns number of removed items. Purged items are no longer known as removed,
+// so list sync could add them again. Items without update time are kept.
+func PurgeDeletedItems() (int, error) {
+	days := config.Get().DeletedItemRetentionDays
+	if days <= 0 {
+		return 0, nil
+	}
+
+	var lis []database.LibraryItem
+	if err := database.GetStormDB().Select(q.Eq("State", StateDeleted)).Find(&lis); err != nil && err != storm.ErrNotFound {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	tx, err := database.GetStormDB().Begin(true)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	purged := 0
+	for i := range lis {
+		if lis[i].UpdatedAt.IsZero() || lis[i].UpdatedAt.After(cutoff) {
+			continue
+		}
+
+		if err := tx.DeleteStruct(&lis[i]); err != nil {
+			return 0, err
+		}
+		purged++
+	}
+	if purged == 0 {
+		return 0, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	log.Infof("Purged %d library items, deleted more than %d days ago", purged, days)
+	return purged, nil
+}
@@ -0,0 +1,168 @@
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// RewriteAllNFO regenerates NFO files of active library items with current NFO writers,
+// strm files are not touched and Kodi is not asked to update the library
+func RewriteAllNFO() error {
+	if err := checkLibraryPath(); err != nil {
+		return err
+	}
+
+	written, skipped := 0, 0
+
+	if config.Get().LibraryNFOMovies {
+		lis, err := ListLibraryItems(MovieType, StateActive)
+		if err != nil {
+			return err
+		}
+
+		for _, li := range lis {
+			if closer.IsSet() {
+				return ErrLibraryClosing
+			}
+
+			movie := tmdb.GetMovie(li.ID, config.Get().StrmLanguage)
+			if movie == nil {
+				skipped++
+				continue
+			}
+
+			paths := getMoviePaths(movie)
+			if len(paths) == 0 {
+				log.Debugf("Skipping NFO of %s, library folder is not found", movie.Title)
+				skipped++
+				continue
+			}
+			for dir := range paths {
+				for _, nfoPath := range movieNFOPaths(dir) {
+					if writeMovieNFO(movie, nfoPath) == nil {
+						written++
+					}
+				}
+			}
+		}
+	}
+
+	if config.Get().LibraryNFOShows || config.Get().LibraryNFOEpisodes {
+		lis, err := ListLibraryItems(ShowType, StateActive)
+		if err != nil {
+			return err
+		}
+
+		for _, li := range lis {
+			if closer.IsSet() {
+				return ErrLibraryClosing
+			}
+
+			show := tmdb.GetShow(li.ID, config.Get().StrmLanguage)
+			if show == nil {
+				skipped++
+				continue
+			}
+
+			paths := getShowPaths(show)
+			if len(paths) == 0 {
+				log.Debugf("Skipping NFO of %s, library folder is not found", show.Name)
+				skipped++
+				continue
+			}
+			for dir := range paths {
+				written += rewriteShowNFO(show, dir)
+			}
+		}
+	}
+
+	log.Noticef("Library NFO files rewritten: %d files written, %d items skipped", written, skipped)
+	return nil
+}
+
+// movieNFOPaths returns NFO files of the movie folder, or of the movie file in flat layout,
+// folder without NFO files gets the one, named after the folder
+func movieNFOPaths(dir string) []string {
+	if isFlatMovieFile(dir) {
+		return []string{strings.TrimSuffix(dir, ".strm") + ".nfo"}
+	}
+
+	ret := []string{}
+	if entries, err := libFS.ReadDir(dir); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() && filepath.Ext(e.Name()) == ".nfo" {
+				ret = append(ret, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	if len(ret) == 0 {
+		ret = append(ret, filepath.Join(dir, filepath.Base(dir)+".nfo"))
+	}
+
+	return ret
+}
+
+// rewriteShowNFO rewrites tvshow.nfo and NFO files of existing episode strm files,
+// returns number of written files
+func rewriteShowNFO(show *tmdb.Show, dir string) (written int) {
+	existing := episodeStrmFiles(dir, filepath.Base(dir))
+
+	if config.Get().LibraryNFOShows {
+		seasons := map[int]bool{}
+		for code := range existing {
+			var season, episode int
+			if n, _ := fmt.Sscanf(code, "S%dE%d", &season, &episode); n == 2 && season > 0 {
+				seasons[season] = true
+			}
+		}
+		if writeShowNFO(show, filepath.Join(dir, "tvshow.nfo"), len(seasons)) == nil {
+			written++
+		}
+	}
+
+	if !config.Get().LibraryNFOEpisodes || len(existing) == 0 {
+		return
+	}
+
+	var absolute func(season, episode int) int
+	if isAnimeAbsolute(show) {
+		absolute = showAbsoluteNumbers(show)
+	}
+
+	seasons := []*tmdb.Season{}
+	for _, season := range show.Seasons {
+		if season != nil && season.EpisodeCount > 0 {
+			seasons = append(seasons, season)
+		}
+	}
+	for i, seasonTMDB := range fetchShowSeasons(show, seasons) {
+		if seasonTMDB == nil {
+			continue
+		}
+
+		number := seasons[i].Season
+		for _, episode := range seasonTMDB.Episodes {
+			if episode == nil {
+				continue
+			}
+
+			paths := existing[episodeCode(number, episode.EpisodeNumber)]
+			if absolute != nil {
+				if an := absolute(number, episode.EpisodeNumber); an > 0 {
+					paths = append(append([]string{}, paths...), existing[absoluteCode(an)]...)
+				}
+			}
+			for _, p := range paths {
+				if writeEpisodeNFO(show, number, episode, episodeNFOName(p)) == nil {
+					written++
+				}
+			}
+		}
+	}
+
+	return
+}
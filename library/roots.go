@@ -0,0 +1,125 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/diskusage"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+const (
+	// RootPolicyPrimary writes new titles into the main library path,
+	// extra library paths only keep titles, that are already there
+	RootPolicyPrimary = iota
+	// RootPolicyFreeSpace writes new titles into the library path with most free space
+	RootPolicyFreeSpace
+)
+
+// LibraryPaths returns main library path, followed by extra library paths
+func LibraryPaths() []string {
+	ret := []string{config.Get().LibraryPath}
+	for _, p := range config.Get().LibraryExtraPaths {
+		if filepath.Clean(p) != filepath.Clean(config.Get().LibraryPath) {
+			ret = append(ret, p)
+		}
+	}
+	return ret
+}
+
+// MoviesLibraryPaths returns folders for movie strm files in all library paths
+func MoviesLibraryPaths() []string {
+	ret := []string{}
+	for _, p := range LibraryPaths() {
+		ret = append(ret, filepath.Join(p, "Movies"))
+	}
+	return ret
+}
+
+// ShowsLibraryPaths returns folders for show strm files in all library paths
+func ShowsLibraryPaths() []string {
+	ret := []string{}
+	for _, p := range LibraryPaths() {
+		ret = append(ret, filepath.Join(p, "Shows"))
+	}
+	return ret
+}
+
+// isMoviesLibraryPath checks if dir is a movies folder of any library path
+func isMoviesLibraryPath(dir string) bool {
+	dir = filepath.Clean(dir)
+	for _, root := range MoviesLibraryPaths() {
+		if dir == filepath.Clean(root) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaRootOf returns movies or shows folder, that contains path, or empty string
+func mediaRootOf(path string, roots []string) string {
+	path = filepath.Clean(path)
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		if strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return root
+		}
+	}
+	return ""
+}
+
+// newTitleRoot returns movies or shows folder for a title, that has no folder yet
+func newTitleRoot(roots []string) string {
+	if len(roots) == 1 || config.Get().LibraryRootPolicy != RootPolicyFreeSpace {
+		return roots[0]
+	}
+
+	ret := roots[0]
+	var free int64 = -1
+	for _, root := range roots {
+		status, err := diskusage.DiskUsage(filepath.Dir(root))
+		if err != nil || status == nil {
+			continue
+		}
+		if status.Free > free {
+			ret, free = root, status.Free
+		}
+	}
+	return ret
+}
+
+// movieBaseRoot returns movies folder for the movie, movie, that is already written, keeps its library path
+func movieBaseRoot(movie *tmdb.Movie) string {
+	roots := MoviesLibraryPaths()
+	if len(roots) == 1 {
+		return roots[0]
+	}
+
+	for p := range getMoviePaths(movie) {
+		if root := mediaRootOf(p, roots); root != "" {
+			return root
+		}
+	}
+	return newTitleRoot(roots)
+}
+
+// ensureMediaFolders creates movies or shows folder in extra library paths and checks they are writable,
+// missing or read-only extra library path is skipped, so unplugged drive does not stop the library
+func ensureMediaFolders(roots []string) {
+	for _, root := range roots[1:] {
+		if _, err := libFS.Stat(filepath.Dir(root)); err != nil {
+			log.Warningf("Extra library path %s is not available: %s", filepath.Dir(root), err)
+			continue
+		}
+		if _, err := libFS.Stat(root); os.IsNotExist(err) {
+			if err := libFS.Mkdir(root, 0755); err != nil {
+				log.Warningf("Could not create library folder %s: %s", root, err)
+				continue
+			}
+		}
+		// Failed probe is logged and notified by checkWritable
+		checkWritable(root)
+	}
+}
@@ -0,0 +1,65 @@
+package library
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+var (
+	scanMu     sync.Mutex
+	scanTimer  *time.Timer
+	scanScopes = map[string]bool{}
+)
+
+// RequestScan asks Kodi to scan library directory, empty scope means whole library.
+// Requests are coalesced and a single scan runs after configured quiet period,
+// so syncing several lists in a row does not start overlapping scans.
+func RequestScan(scope string) {
+	delay := config.Get().ScanDebounceSeconds
+	if delay <= 0 {
+		runScan(map[string]bool{scope: true})
+		return
+	}
+
+	scanMu.Lock()
+	defer scanMu.Unlock()
+
+	scanScopes[scope] = true
+	if scanTimer == nil {
+		scanTimer = time.AfterFunc(time.Duration(delay)*time.Second, flushScan)
+	} else {
+		scanTimer.Reset(time.Duration(delay) * time.Second)
+	}
+}
+
+func flushScan() {
+	scanMu.Lock()
+	scopes := scanScopes
+	scanScopes = map[string]bool{}
+	scanTimer = nil
+	scanMu.Unlock()
+
+	if closer.IsSet() || len(scopes) == 0 {
+		return
+	}
+
+	runScan(scopes)
+}
+
+func runScan(scopes map[string]bool) {
+	if len(scopes) == 1 {
+		for scope := range scopes {
+			if scope != "" {
+				log.Debugf("Starting Kodi library scan for %s", scope)
+				xbmc.VideoLibraryScanDirectory(scope, false)
+				return
+			}
+		}
+	}
+
+	log.Debugf("Starting Kodi library scan")
+	xbmc.VideoLibraryScan()
+}
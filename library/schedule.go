@@ -0,0 +1,146 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+)
+
+// listSchedule keeps sync state of a single Trakt list for a media type
+type listSchedule struct {
+	MediaType int
+	User      string
+	ListID    string
+	LastRun   time.Time
+	Deferred  bool
+}
+
+var scheduleMu sync.Mutex
+
+// listScheduleKey keys schedule by list origin, so same list slugs of different owners do not collide
+func listScheduleKey(mediaType int, user, listID string) string {
+	if mediaType == MovieType {
+		return "movie/" + listOrigin(user, listID)
+	}
+	return "show/" + listOrigin(user, listID)
+}
+
+// loadListSchedules returns stored schedules, keyed with listScheduleKey,
+// schedules, stored under older keys, are moved to their current keys
+func loadListSchedules() map[string]*listSchedule {
+	stored := map[string]*listSchedule{}
+	cache.NewDBStore().Get(cache.TraktListSchedulesKey, &stored)
+
+	ret := map[string]*listSchedule{}
+	for _, s := range stored {
+		key := listScheduleKey(s.MediaType, s.User, s.ListID)
+		if prev, ok := ret[key]; !ok || prev.LastRun.Before(s.LastRun) {
+			ret[key] = s
+		}
+	}
+	return ret
+}
+
+func saveListSchedules(schedules map[string]*listSchedule) {
+	cache.NewDBStore().Set(cache.TraktListSchedulesKey, schedules, cache.TraktListSchedulesExpire)
+}
+
+// isListDue checks configured interval of the list. List, that is not due yet,
+// is marked as deferred, to be synced on one of next ticks.
+func isListDue(mediaType int, user, listID string) bool {
+	interval := config.Get().TraktListIntervals[listID]
+	if interval <= 0 {
+		return true
+	}
+
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	schedules := loadListSchedules()
+	key := listScheduleKey(mediaType, user, listID)
+	s, ok := schedules[key]
+	if !ok || time.Since(s.LastRun) >= time.Duration(interval)*time.Minute {
+		return true
+	}
+
+	if !s.Deferred {
+		s.Deferred = true
+		s.User = user
+		saveListSchedules(schedules)
+	}
+
+	return false
+}
+
+// markListSynced stores time of the list sync
+func markListSynced(mediaType int, user, listID string) {
+	if config.Get().TraktListIntervals[listID] <= 0 {
+		return
+	}
+
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	schedules := loadListSchedules()
+	schedules[listScheduleKey(mediaType, user, listID)] = &listSchedule{
+		MediaType: mediaType,
+		User:      user,
+		ListID:    listID,
+		LastRun:   time.Now(),
+	}
+	saveListSchedules(schedules)
+}
+
+// syncListScheduled runs list sync for a media type, if the list is due
+func syncListScheduled(mediaType int, user, listID string, isUpdateNeeded bool) (err error) {
+	if !isListDue(mediaType, user, listID) {
+		log.Debugf("TraktSync: list %s is not due yet, deferring", listScheduleKey(mediaType, user, listID))
+		return nil
+	}
+
+	// Scheduled syncs are updates, so movies removed by the user are not written again
+	if mediaType == MovieType {
+		err = syncMoviesList(context.Background(), user, listID, true, isUpdateNeeded, nil)
+	} else {
+		err = syncShowsList(context.Background(), user, listID, false, isUpdateNeeded, nil)
+	}
+
+	if err == nil {
+		markListSynced(mediaType, user, listID)
+	}
+	return
+}
+
+// RunDeferredLists syncs lists, that were skipped before due to their intervals and are due now
+func RunDeferredLists() error {
+	scheduleMu.Lock()
+	due := []*listSchedule{}
+	for _, s := range loadListSchedules() {
+		interval := config.Get().TraktListIntervals[s.ListID]
+		if s.Deferred && (interval <= 0 || time.Since(s.LastRun) >= time.Duration(interval)*time.Minute) {
+			due = append(due, s)
+		}
+	}
+	scheduleMu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].LastRun.Before(due[j].LastRun) })
+
+	var lastErr error
+	for _, s := range due {
+		if closer.IsSet() {
+			return ErrLibraryClosing
+		}
+
+		if err := syncListScheduled(s.MediaType, s.User, s.ListID, true); err != nil {
+			log.Warningf("TraktSync: Got error syncing deferred list %s: %s", listScheduleKey(s.MediaType, s.User, s.ListID), err)
+			lastErr = fmt.Errorf("Deferred list %s failed: %s", s.ListID, err)
+		}
+	}
+
+	return lastErr
+}
@@ -0,0 +1,29 @@
+package library
+
+import (
+	"testing"
+
+	"github.com/elgatito/elementum/config"
+)
+
+func TestListScheduleOwners(t *testing.T) {
+	setupLibraryDB(t)
+
+	conf := config.Get()
+	saved := *conf
+	conf.TraktUsername = "me"
+	conf.TraktListIntervals = map[string]int{"favorites": 60}
+	defer func() { *conf = saved }()
+
+	markListSynced(MovieType, "bob", "favorites")
+
+	if isListDue(MovieType, "bob", "favorites") {
+		t.Error("list of bob is due right after its sync")
+	}
+	if !isListDue(MovieType, "alice", "favorites") {
+		t.Error("list of alice with the same slug is not due, schedules collide")
+	}
+	if !isListDue(ShowType, "bob", "favorites") {
+		t.Error("shows of the list are not due after movies sync")
+	}
+}
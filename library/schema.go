@@ -0,0 +1,77 @@
+package library
+
+import (
+	"github.com/asdine/storm"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/database"
+)
+
+// librarySchemaVersion is the current version of library items in the database,
+// version 2 adds AddedAt, that is left zero for old items, as their addition time is unknown,
+// version 4 adds Title and Year, that are left empty for old items until they are rewritten
+const librarySchemaVersion = 4
+
+// schemaMigrations holds migrations, keyed by the version they migrate to
+var schemaMigrations = map[int]func(tx storm.Node) error{
+	3: migrateOrigin,
+}
+
+// MigrateSchema brings library items, stored by older versions, to the current schema
+func MigrateSchema() error {
+	if cacheStore == nil {
+		InitDB()
+	}
+
+	version := 1
+	if err := cacheStore.Get(cache.LibrarySchemaVersionKey, &version); err != nil {
+		version = 1
+	}
+	if version >= librarySchemaVersion {
+		return nil
+	}
+
+	log.Infof("Migrating library database schema from version %d to %d", version, librarySchemaVersion)
+
+	tx, err := database.GetStormDB().Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for v := version + 1; v <= librarySchemaVersion; v++ {
+		if migrate, ok := schemaMigrations[v]; ok {
+			if err := migrate(tx); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return cacheStore.Set(cache.LibrarySchemaVersionKey, librarySchemaVersion, cache.LibrarySchemaVersionExpire)
+}
+
+// migrateOrigin marks items, added before origin was tracked, as added by the user,
+// so they are never removed by list sync
+func migrateOrigin(tx storm.Node) error {
+	var lis []database.LibraryItem
+	if err := tx.All(&lis); err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	for _, li := range lis {
+		if li.Origin != "" || (li.MediaType != MovieType && li.MediaType != ShowType) {
+			continue
+		}
+
+		li.Origin = originManual
+		if err := tx.Save(&li); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
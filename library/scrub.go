@@ -0,0 +1,249 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/library/uid"
+)
+
+var (
+	scrubMu      sync.Mutex
+	scrubRunning bool
+
+	// ErrScrubRunning is returned when another scrub run is not finished yet
+	ErrScrubRunning = errors.New("Library scrub is already running")
+)
+
+// Scrub checks library folders for integrity issues.
+// Safe issues (empty folders, corrupt strm files) are fixed when repair is set,
+// unsafe ones (missing titles, leftovers of removed titles) are only reported.
+func Scrub(repair bool) (*ScrubReport, error) {
+	scrubMu.Lock()
+	if scrubRunning {
+		scrubMu.Unlock()
+		return nil, ErrScrubRunning
+	}
+	scrubRunning = true
+	scrubMu.Unlock()
+
+	defer func() {
+		scrubMu.Lock()
+		scrubRunning = false
+		scrubMu.Unlock()
+	}()
+
+	if err := checkLibraryPath(); err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	report := &ScrubReport{
+		EmptyDirs:     []string{},
+		CorruptStrm:   []string{},
+		MissingMovies: []int{},
+		MissingShows:  []int{},
+		StaleRemoved:  []string{},
+	}
+
+	// Collect Elementum strm files, that exist on disk, per library item
+	onDisk := map[int]map[int][]string{
+		MovieType: {},
+		ShowType:  {},
+	}
+	roots := append(MoviesLibraryPaths(), ShowsLibraryPaths()...)
+	manifested := manifestItemsByPath(roots)
+	for _, root := range roots {
+		for _, f := range searchAllStrm(root) {
+			if closer.IsSet() {
+				return report, nil
+			}
+
+			content, err := libFS.ReadFile(f)
+			if err != nil {
+				continue
+			}
+
+			link, err := ResolvePlayLink(string(content))
+			if err != nil {
+				report.CorruptStrm = append(report.CorruptStrm, f)
+				if repair && repairStrm(f, manifested) {
+					report.Repaired++
+				}
+				continue
+			}
+
+			mediaType := link.MediaType
+			if mediaType == EpisodeType {
+				mediaType = ShowType
+			}
+			onDisk[mediaType][link.TMDBID] = append(onDisk[mediaType][link.TMDBID], f)
+		}
+	}
+
+	if repair {
+		removed, _ := CleanupEmptyDirectories(false)
+		report.EmptyDirs = append(report.EmptyDirs, removed...)
+		report.Repaired += len(removed)
+	} else {
+		report.EmptyDirs, _ = CleanupEmptyDirectories(true)
+	}
+
+	var lis []database.LibraryItem
+	if err := database.GetStormDB().Select(q.Or(q.Eq("MediaType", MovieType), q.Eq("MediaType", ShowType))).Find(&lis); err != nil && err != storm.ErrNotFound {
+		return report, err
+	}
+
+	for _, li := range lis {
+		files := onDisk[li.MediaType][li.ID]
+		if li.State == StateActive && len(files) == 0 {
+			if li.MediaType == MovieType {
+				report.MissingMovies = append(report.MissingMovies, li.ID)
+			} else {
+				report.MissingShows = append(report.MissingShows, li.ID)
+			}
+		} else if li.State == StateDeleted && len(files) > 0 {
+			report.StaleRemoved = append(report.StaleRemoved, files...)
+		}
+	}
+
+	log.Infof("Library scrub finished in %s: %d empty folders, %d corrupt strm files, %d missing movies, %d missing shows, %d leftover files of removed titles, %d issues repaired",
+		time.Since(started), len(report.EmptyDirs), len(report.CorruptStrm), len(report.MissingMovies), len(report.MissingShows), len(report.StaleRemoved), report.Repaired)
+
+	return report, nil
+}
+
+// repairStrm rewrites corrupt strm file with a play link, based on Kodi library information.
+// Only files of Elementum titles are repaired: title folder should be listed in the manifest,
+// or other strm files in the folder should point to the same title, anything else is only reported.
+func repairStrm(path string, manifested map[string]*ManifestItem) bool {
+	var playLink string
+	var mediaType, tmdbID int
+
+	l := uid.Get()
+	l.Mu.Movies.RLock()
+	for _, m := range l.Movies {
+		if m != nil && m.UIDs != nil && m.UIDs.TMDB != 0 && m.File == path {
+			playLink = moviePlayLink(m.UIDs.TMDB)
+			mediaType, tmdbID = MovieType, m.UIDs.TMDB
+			break
+		}
+	}
+	l.Mu.Movies.RUnlock()
+
+	if playLink == "" {
+		l.Mu.Shows.RLock()
+		for _, s := range l.Shows {
+			if s == nil || s.UIDs == nil || s.UIDs.TMDB == 0 {
+				continue
+			}
+			for _, e := range s.Episodes {
+				if e != nil && e.File == path {
+					playLink = episodePlayLink(s.UIDs.TMDB, e.Season, e.Episode)
+					mediaType, tmdbID = ShowType, s.UIDs.TMDB
+					break
+				}
+			}
+			if playLink != "" {
+				break
+			}
+		}
+		l.Mu.Shows.RUnlock()
+	}
+
+	if playLink == "" {
+		log.Warningf("Could not find library item for corrupt strm file %s", path)
+		return false
+	}
+
+	dir := filepath.Dir(path)
+	if mediaType == ShowType {
+		dir = showPathOf(path)
+	}
+	if mi, ok := manifested[filepath.Clean(dir)]; ok {
+		if mi.TMDBID != tmdbID || mi.MediaType != mediaType {
+			log.Warningf("Not repairing corrupt strm file %s, its folder belongs to another title", path)
+			return false
+		}
+	} else if !folderBelongsTo(dir, mediaType, tmdbID) {
+		log.Warningf("Not repairing corrupt strm file %s, its folder is not known to be written by Elementum", path)
+		return false
+	}
+
+	if err := libFS.WriteFile(path, []byte(playLink), 0644); err != nil {
+		log.Warningf("Could not repair strm file %s: %s", path, err)
+		return false
+	}
+
+	log.Infof("Repaired strm file %s", path)
+	return true
+}
+
+// runScrub is launched by the scrub ticker
+func runScrub() {
+	report, err := Scrub(config.Get().ScrubAutoRepair)
+	if err != nil {
+		log.Warningf("Library scrub failed: %s", err)
+		return
+	}
+
+	unsafe := len(report.MissingMovies) + len(report.MissingShows) + len(report.StaleRemoved)
+	if !config.Get().ScrubAutoRepair {
+		unsafe += len(report.EmptyDirs) + len(report.CorruptStrm)
+	}
+	if unsafe > 0 {
+		notify(fmt.Sprintf("Library scrub found %d issues, check the log for details", unsafe))
+		if len(report.MissingMovies) > 0 || len(report.MissingShows) > 0 {
+			log.Warningf("Library titles without strm files: movies=%v, shows=%v", report.MissingMovies, report.MissingShows)
+		}
+		if len(report.StaleRemoved) > 0 {
+			log.Warningf("Files of removed titles left in the library: %s", strings.Join(report.StaleRemoved, ", "))
+		}
+	}
+}
+
+// CleanupEmptyDirectories removes empty title folders in library roots and returns them,
+// with dryRun set folders are only reported
+func CleanupEmptyDirectories(dryRun bool) ([]string, error) {
+	if err := checkLibraryPath(); err != nil {
+		return nil, err
+	}
+
+	ret := []string{}
+	for _, root := range append(MoviesLibraryPaths(), ShowsLibraryPaths()...) {
+		entries, err := libFS.ReadDir(root)
+		if err != nil {
+			continue
+		}
+
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+
+			dir := filepath.Join(root, e.Name())
+			if children, err := libFS.ReadDir(dir); err != nil || len(children) > 0 {
+				continue
+			}
+
+			if !dryRun {
+				if err := safeRemoveAll(dir); err != nil {
+					log.Warningf("Could not remove empty directory %s: %s", dir, err)
+					continue
+				}
+			}
+			ret = append(ret, dir)
+		}
+	}
+
+	return ret, nil
+}
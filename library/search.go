@@ -0,0 +1,71 @@
+package library
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// SearchLibrary returns active library movies and shows, which title contains the query,
+// case-insensitive. Stored titles are used, items without them are resolved with TMDB.
+func SearchLibrary(query string) ([]LibrarySearchResult, error) {
+	ret := []LibrarySearchResult{}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return ret, nil
+	}
+
+	var lis []database.LibraryItem
+	if err := database.GetStormDB().Select(q.Or(q.Eq("MediaType", MovieType), q.Eq("MediaType", ShowType)), q.Eq("State", StateActive)).Find(&lis); err != nil && err != storm.ErrNotFound {
+		return nil, err
+	}
+
+	for _, li := range lis {
+		if closer.IsSet() {
+			return ret, ErrLibraryClosing
+		}
+
+		if title, ok := matchItemTitle(li, query); ok {
+			ret = append(ret, LibrarySearchResult{
+				TMDBID:    li.ID,
+				MediaType: li.MediaType,
+				Title:     title,
+			})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return strings.ToLower(ret[i].Title) < strings.ToLower(ret[j].Title)
+	})
+	return ret, nil
+}
+
+// matchItemTitle returns title of the library item, that matches lowercased query,
+// for items without stored title TMDB title and original title are checked
+func matchItemTitle(li database.LibraryItem, query string) (string, bool) {
+	titles := []string{}
+	if li.Title != "" {
+		titles = append(titles, li.Title)
+	} else if li.MediaType == MovieType {
+		if movie := tmdb.GetMovieByID(strconv.Itoa(li.ID), config.Get().Language); movie != nil {
+			titles = append(titles, movie.Title, movie.OriginalTitle)
+		}
+	} else if show := tmdb.GetShow(li.ID, config.Get().Language); show != nil {
+		titles = append(titles, show.Name, show.OriginalName)
+	}
+
+	for _, title := range titles {
+		if title != "" && strings.Contains(strings.ToLower(title), query) {
+			return title, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,51 @@
+package library
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	"github.com/elgatito/elementum/database"
+)
+
+// GetLibraryStats counts library items by type and state and measures library folders
+func GetLibraryStats() (LibraryStats, error) {
+	stats := LibraryStats{}
+
+	lis, err := ListLibraryItems(-1, -1)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, li := range lis {
+		if li.State == StateDeleted {
+			stats.DeletedItems++
+			continue
+		}
+
+		switch li.MediaType {
+		case MovieType:
+			stats.ActiveMovies++
+		case ShowType:
+			stats.ActiveShows++
+		case EpisodeType:
+			stats.ActiveEpisodes++
+		}
+	}
+
+	var items []database.BTItem
+	if err := database.GetStormDB().Select(q.Eq("State", database.StateDeleted)).Find(&items); err != nil && err != storm.ErrNotFound {
+		return stats, err
+	}
+	stats.PendingRemovals = len(items)
+
+	for _, root := range MoviesLibraryPaths() {
+		bytes, _ := dirUsage(root)
+		stats.MoviesBytes += bytes
+	}
+	for _, root := range ShowsLibraryPaths() {
+		bytes, _ := dirUsage(root)
+		stats.ShowsBytes += bytes
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,159 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+func TestRemovedMovieSync(t *testing.T) {
+	tests := []struct {
+		name      string
+		updating  bool
+		isRemoved bool
+		skip      bool
+		force     bool
+	}{
+		{"scheduled update skips removed movie", true, true, true, true},
+		{"explicit add rewrites removed movie", false, true, false, true},
+		{"scheduled update writes active movie", true, false, false, false},
+		{"explicit add writes active movie", false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skip, force := removedMovieSync(tt.updating, tt.isRemoved)
+			if skip != tt.skip {
+				t.Errorf("skip = %v, want %v", skip, tt.skip)
+			}
+			if !skip && force != tt.force {
+				t.Errorf("force = %v, want %v", force, tt.force)
+			}
+		})
+	}
+}
+
+// setupLibraryDB opens library and cache databases in a temporary profile folder,
+// databases are closed, profile is restored and the folder is removed when the test finishes
+func setupLibraryDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "elementum-library")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := config.Get()
+	saved := conf.Info
+	info := xbmc.AddonInfo{}
+	if saved != nil {
+		info = *saved
+	}
+	info.Profile = dir
+	conf.Info = &info
+
+	storm, err := database.InitStormDB(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bolt, err := database.InitCacheDB(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	InitDB()
+
+	t.Cleanup(func() {
+		bolt.Close()
+		storm.Close()
+		conf.Info = saved
+		os.RemoveAll(dir)
+	})
+}
+
+// memStrmFiles returns strm files under the folder
+func memStrmFiles(t *testing.T, m *MemFS, dir string) (ret []string) {
+	fis, err := m.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fi := range fis {
+		p := filepath.Join(dir, fi.Name())
+		if fi.IsDir() {
+			ret = append(ret, memStrmFiles(t, m, p)...)
+		} else if strings.HasSuffix(p, ".strm") {
+			ret = append(ret, p)
+		}
+	}
+	return
+}
+
+func TestSyncRemovedMovie(t *testing.T) {
+	m := setupMemLibrary(t)
+	setupLibraryDB(t)
+
+	conf := config.Get()
+	conf.LibraryNFOMovies = false
+	conf.LibraryDownloadArtwork = false
+	conf.LibraryManifest = false
+	conf.LibraryUpdate = 2
+	conf.MovieGroupBySet = false
+	conf.MovieFolderGrouping = 0
+	conf.MovieLibraryFlat = false
+	conf.MovieStrmParts = 1
+
+	movie := &tmdb.Movie{}
+	movie.ID = 42
+	movie.Title = "Removed Movie"
+	movie.ReleaseDate = "2020-01-01"
+	store := cache.NewDBStore()
+	for _, lang := range []string{conf.Language, conf.StrmLanguage} {
+		if err := store.Set(fmt.Sprintf(cache.TMDBMovieByIDKey, "42", lang), movie, cache.TMDBMovieByIDExpire); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := []*trakt.Movies{
+		{Movie: &trakt.Movie{Object: trakt.Object{Title: movie.Title, IDs: &trakt.IDs{Trakt: 7, TMDB: 42}, UpdatedAt: time.Now()}}},
+	}
+	if err := store.Set(fmt.Sprintf(cache.TraktMoviesListKey, "removed"), list, cache.TraktMoviesListExpire); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := updateDBItem(42, StateDeleted, MovieType, 0); err != nil {
+		t.Fatal(err)
+	}
+	moviesRoot := MoviesLibraryPaths()[0]
+
+	if err := syncMoviesList(context.Background(), "", "removed", true, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if files := memStrmFiles(t, m, moviesRoot); len(files) != 0 {
+		t.Errorf("scheduled sync wrote %v for removed movie", files)
+	}
+
+	if _, _, err := AddMovieEx("42", false, ""); err != ErrVideoRemoved {
+		t.Errorf("AddMovieEx() of removed movie = %v, want %v", err, ErrVideoRemoved)
+	}
+	if files := memStrmFiles(t, m, moviesRoot); len(files) != 0 {
+		t.Errorf("add without force wrote %v for removed movie", files)
+	}
+
+	if err := syncMoviesList(context.Background(), "", "removed", false, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if files := memStrmFiles(t, m, moviesRoot); len(files) != 1 {
+		t.Errorf("explicit list add wrote %v, want one strm file", files)
+	}
+	if WasRemoved(42, MovieType) {
+		t.Error("movie is still marked as removed after explicit list add")
+	}
+}
@@ -0,0 +1,73 @@
+package library
+
+import (
+	"fmt"
+
+	"github.com/elgatito/elementum/cache"
+)
+
+// syncCursorInterval is a number of list items, processed between cursor checkpoints
+const syncCursorInterval = 10
+
+// syncCursor is a durable position of Trakt list sync,
+// so sync, interrupted by a restart, resumes instead of walking the whole list again
+type syncCursor struct {
+	key string
+
+	// Index of the next list item to process, TraktID of the last processed one
+	Index   int
+	TraktID int
+	// Written and New keep ids, collected before the checkpoint, to store them on completion
+	Written []int
+	New     []int
+}
+
+// loadSyncCursor returns saved cursor of the list, or an empty one
+func loadSyncCursor(mediaType int, user, listID string) *syncCursor {
+	c := &syncCursor{}
+	if err := cacheStore.Get(fmt.Sprintf(cache.LibrarySyncCursorKey, mediaType, user, listID), c); err != nil {
+		c = &syncCursor{}
+	}
+	c.key = fmt.Sprintf(cache.LibrarySyncCursorKey, mediaType, user, listID)
+	return c
+}
+
+// resumeIndex returns index of the first list item to process.
+// List could change since the checkpoint, so last processed item is looked up by Trakt id.
+func (c *syncCursor) resumeIndex(traktIDs []int) int {
+	if c.TraktID == 0 {
+		return 0
+	}
+	if c.Index > 0 && c.Index <= len(traktIDs) && traktIDs[c.Index-1] == c.TraktID {
+		return c.Index
+	}
+	for i, id := range traktIDs {
+		if id == c.TraktID {
+			return i + 1
+		}
+	}
+
+	// Last processed item is gone from the list, so ids, collected before, are not reliable
+	c.Written, c.New = nil, nil
+	return 0
+}
+
+// checkpoint saves position before the list item with index n
+func (c *syncCursor) checkpoint(n int, traktIDs []int, written, added []int) {
+	if n == 0 || n > len(traktIDs) {
+		return
+	}
+
+	c.Index = n
+	c.TraktID = traktIDs[n-1]
+	c.Written = written
+	c.New = added
+	if err := cacheStore.Set(c.key, c, cache.LibrarySyncCursorExpire); err != nil {
+		log.Debugf("Could not save sync cursor %s: %s", c.key, err)
+	}
+}
+
+// done removes the cursor after the list is completely processed
+func (c *syncCursor) done() {
+	cacheStore.Delete(c.key)
+}
@@ -0,0 +1,54 @@
+package library
+
+import (
+	"strconv"
+
+	"github.com/elgatito/elementum/database"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// ItemTitle is a title and year, stored with library item to display the library without TMDB requests
+type ItemTitle struct {
+	Title string
+	Year  int
+}
+
+func movieItemTitle(movie *tmdb.Movie) ItemTitle {
+	year, _ := strconv.Atoi(getMovieYear(movie))
+	return ItemTitle{Title: movie.Title, Year: year}
+}
+
+func showItemTitle(show *tmdb.Show) ItemTitle {
+	year, _ := strconv.Atoi(getShowYear(show))
+	return ItemTitle{Title: show.Name, Year: year}
+}
+
+// setItemTitles stores titles of library items of the media type, items, that are not stored yet,
+// or are stored with another media type, are skipped, database is written only when any title has changed
+func setItemTitles(mediaType int, titles map[int]ItemTitle) error {
+	tx, err := database.GetStormDB().Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	changed := false
+	for id, t := range titles {
+		var li database.LibraryItem
+		if err := tx.One("ID", id, &li); err != nil || li.MediaType != mediaType || (li.Title == t.Title && li.Year == t.Year) {
+			continue
+		}
+
+		li.Title = t.Title
+		li.Year = t.Year
+		if err := tx.Save(&li); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	return tx.Commit()
+}
@@ -1,8 +1,10 @@
 package library
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cespare/xxhash"
@@ -11,9 +13,15 @@ import (
 	"github.com/elgatito/elementum/library/uid"
 	"github.com/elgatito/elementum/tmdb"
 	"github.com/elgatito/elementum/trakt"
+	"github.com/elgatito/elementum/util"
 	"github.com/elgatito/elementum/xbmc"
 )
 
+const (
+	traktRetryAttempts = 5
+	traktRetryDelay    = 2 * time.Second
+)
+
 var (
 	// IsTraktInitialized used to mark if we need only incremental updates from Trakt
 	IsTraktInitialized bool
@@ -64,6 +72,11 @@ func RefreshTrakt() error {
 	var previousActivities trakt.UserActivities
 	_ = cacheStore.Get(cache.TraktActivitiesKey, &previousActivities)
 
+	// Lists, deferred due to their intervals, are synced regardless of activities
+	if err := RunDeferredLists(); err != nil {
+		log.Warning(err)
+	}
+
 	// If nothing changed from last check - skip everything
 	isFirstRun := !IsTraktInitialized || isKodiUpdated
 	if !lastActivities.All.After(previousActivities.All) && !isFirstRun {
@@ -168,6 +181,11 @@ func RefreshTrakt() error {
 			isErrored = true
 		}
 	}
+	if isFirstRun || lastActivities.Lists.LikedAt.After(previousActivities.Lists.LikedAt) {
+		if err := SyncLikedLists(lastActivities.Lists.LikedAt.After(previousActivities.Lists.LikedAt)); err != nil {
+			isErrored = true
+		}
+	}
 
 	return nil
 }
@@ -646,12 +664,12 @@ func RefreshTraktCollected(itemType int, isRefreshNeeded bool) error {
 	}
 
 	if itemType == MovieType {
-		if err := SyncMoviesList("collection", false, isRefreshNeeded); err != nil {
+		if err := syncListScheduled(MovieType, "", "collection", isRefreshNeeded); err != nil {
 			log.Warningf("TraktSync: Got error from SyncMoviesList for Collection: %s", err)
 			return err
 		}
 	} else if itemType == EpisodeType || itemType == SeasonType || itemType == ShowType {
-		if err := SyncShowsList("collection", false, isRefreshNeeded); err != nil {
+		if err := syncListScheduled(ShowType, "", "collection", isRefreshNeeded); err != nil {
 			log.Warningf("TraktSync: Got error from SyncShowsList for Collection: %s", err)
 			return err
 		}
@@ -667,12 +685,12 @@ func RefreshTraktWatchlisted(itemType int, isRefreshNeeded bool) error {
 	}
 
 	if itemType == MovieType {
-		if err := SyncMoviesList("watchlist", false, isRefreshNeeded); err != nil {
+		if err := syncListScheduled(MovieType, "", "watchlist", isRefreshNeeded); err != nil {
 			log.Warningf("TraktSync: Got error from SyncMoviesList for Watchlist: %s", err)
 			return err
 		}
 	} else if itemType == EpisodeType || itemType == SeasonType || itemType == ShowType {
-		if err := SyncShowsList("watchlist", false, isRefreshNeeded); err != nil {
+		if err := syncListScheduled(ShowType, "", "watchlist", isRefreshNeeded); err != nil {
 			log.Warningf("TraktSync: Got error from SyncShowsList for Watchlist: %s", err)
 			return err
 		}
@@ -797,13 +815,168 @@ func RefreshTraktLists(isRefreshNeeded bool) error {
 
 	lists := trakt.Userlists()
 	for _, list := range lists {
-		if err := SyncMoviesList(strconv.Itoa(list.IDs.Trakt), false, isRefreshNeeded); err != nil {
+		if err := syncListScheduled(MovieType, "", strconv.Itoa(list.IDs.Trakt), isRefreshNeeded); err != nil {
 			continue
 		}
-		if err := SyncShowsList(strconv.Itoa(list.IDs.Trakt), false, isRefreshNeeded); err != nil {
+		if err := syncListScheduled(ShowType, "", strconv.Itoa(list.IDs.Trakt), isRefreshNeeded); err != nil {
 			continue
 		}
 	}
 
 	return nil
 }
+
+// SyncLikedLists syncs lists, liked by the user on Trakt, into the library.
+// If no liked lists are enabled in settings, all of them are synced.
+func SyncLikedLists(isUpdateNeeded bool) error {
+	if config.Get().TraktToken == "" || !config.Get().TraktSyncLikedlists {
+		return nil
+	}
+
+	for _, list := range trakt.Likedlists() {
+		if list == nil || list.IDs == nil || list.User == nil {
+			continue
+		}
+
+		listID := strconv.Itoa(list.IDs.Trakt)
+		if !IsLikedListEnabled(listID) {
+			continue
+		}
+
+		// Lists can contain both movies and shows
+		if err := syncListScheduled(MovieType, list.User.Ids.Slug, listID, isUpdateNeeded); err != nil {
+			log.Warningf("TraktSync: Got error from SyncMoviesList for liked list %s: %s", listID, err)
+		}
+		if err := syncListScheduled(ShowType, list.User.Ids.Slug, listID, isUpdateNeeded); err != nil {
+			log.Warningf("TraktSync: Got error from SyncShowsList for liked list %s: %s", listID, err)
+		}
+	}
+
+	return nil
+}
+
+// IsLikedListEnabled checks if liked Trakt list should be synced
+func IsLikedListEnabled(listID string) bool {
+	ids := config.Get().TraktSyncLikedlistsIDs
+	return len(ids) == 0 || util.StringSliceContains(ids, listID)
+}
+
+// SetLikedListEnabled enables or disables sync of a liked Trakt list and saves it into settings
+func SetLikedListEnabled(listID string, enabled bool) {
+	ids := []string{}
+	for _, id := range config.Get().TraktSyncLikedlistsIDs {
+		if id != listID {
+			ids = append(ids, id)
+		}
+	}
+	if enabled {
+		ids = append(ids, listID)
+	}
+
+	config.Get().TraktSyncLikedlistsIDs = ids
+	xbmc.SetSetting("trakt_sync_likedlists_ids", strings.Join(ids, ","))
+}
+
+// QuickSyncCheck compares only item counts of each synced Trakt list with the library,
+// without resolving missing IDs, and returns lists that are likely out of sync.
+// Map keys are in "movie/<list>" and "show/<list>" form.
+func QuickSyncCheck() (map[string]bool, error) {
+	if config.Get().TraktToken == "" || !config.Get().TraktAuthorized {
+		return nil, errors.New("Trakt is not authorized")
+	}
+
+	listIDs := []string{}
+	if config.Get().TraktSyncWatchlist {
+		listIDs = append(listIDs, "watchlist")
+	}
+	if config.Get().TraktSyncCollections {
+		listIDs = append(listIDs, "collection")
+	}
+	if config.Get().TraktSyncUserlists {
+		for _, list := range trakt.Userlists() {
+			listIDs = append(listIDs, strconv.Itoa(list.IDs.Trakt))
+		}
+	}
+
+	ret := map[string]bool{}
+	for _, listID := range listIDs {
+		if movies, err := traktListMovies("", listID, false); err != nil {
+			log.Warningf("Could not get Trakt movies list %s: %s", listID, err)
+		} else {
+			traktCount, libraryCount := 0, 0
+			for _, m := range movies {
+				if m.Movie == nil || m.Movie.IDs == nil || m.Movie.IDs.TMDB == 0 {
+					continue
+				}
+
+				traktCount++
+				if uid.IsDuplicateMovie(strconv.Itoa(m.Movie.IDs.TMDB)) || WasRemoved(m.Movie.IDs.TMDB, MovieType) {
+					libraryCount++
+				}
+			}
+			ret[movieType+"/"+listID] = libraryCount != traktCount
+		}
+
+		if shows, err := traktListShows("", listID, false); err != nil {
+			log.Warningf("Could not get Trakt shows list %s: %s", listID, err)
+		} else {
+			traktCount, libraryCount := 0, 0
+			for _, s := range shows {
+				if s.Show == nil || s.Show.IDs == nil || s.Show.IDs.TMDB == 0 {
+					continue
+				}
+
+				traktCount++
+				if uid.IsDuplicateShowByInt(s.Show.IDs.TMDB) || WasRemoved(s.Show.IDs.TMDB, ShowType) {
+					libraryCount++
+				}
+			}
+			ret[showType+"/"+listID] = libraryCount != traktCount
+		}
+	}
+
+	return ret, nil
+}
+
+// withTraktRetry repeats f while Trakt answers with rate limit, doubling the delay
+// between attempts. Retry-After header is already honored by the trakt package on each call.
+func withTraktRetry(what string, f func() error) (err error) {
+	delay := traktRetryDelay
+	for attempt := 1; ; attempt++ {
+		if err = f(); err != trakt.ErrRateLimited {
+			return
+		} else if attempt > traktRetryAttempts {
+			return fmt.Errorf("Trakt rate limit exceeded getting %s, giving up after %d retries", what, traktRetryAttempts)
+		}
+
+		log.Warningf("Trakt rate limit exceeded getting %s, retrying in %s", what, delay)
+		select {
+		case <-closer.C():
+			return ErrLibraryClosing
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func traktListMovies(user string, listID string, isUpdateNeeded bool) ([]*trakt.Movies, error) {
+	switch listID {
+	case "watchlist":
+		return trakt.WatchlistMovies(isUpdateNeeded)
+	case "collection":
+		return trakt.CollectionMovies(isUpdateNeeded)
+	default:
+		return trakt.ListItemsMovies(user, listID, isUpdateNeeded)
+	}
+}
+
+func traktListShows(user string, listID string, isUpdateNeeded bool) ([]*trakt.Shows, error) {
+	switch listID {
+	case "watchlist":
+		return trakt.WatchlistShows(isUpdateNeeded)
+	case "collection":
+		return trakt.CollectionShows(isUpdateNeeded)
+	default:
+		return trakt.ListItemsShows(user, listID, isUpdateNeeded)
+	}
+}
@@ -0,0 +1,274 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+)
+
+// trashEntry describes title folder, moved to trash instead of removal
+type trashEntry struct {
+	MediaType    int
+	TMDBID       int
+	OriginalPath string
+	TrashedAt    time.Time
+	// Flat is set for movie files of flat layout, trashed into a folder of their own,
+	// OriginalPath is the strm file then
+	Flat bool
+}
+
+var trashMu sync.Mutex
+
+// ErrNotInTrash is returned when there is nothing to restore for the title
+var ErrNotInTrash = errors.New("Nothing to restore from trash")
+
+// TrashLibraryPaths returns trash folders of all library paths
+func TrashLibraryPaths() []string {
+	ret := []string{}
+	for _, p := range LibraryPaths() {
+		ret = append(ret, filepath.Join(p, ".trash"))
+	}
+	return ret
+}
+
+// trashPathOf returns trash folder of the library path, that contains path,
+// so moving to trash is a rename within the same drive
+func trashPathOf(path string) string {
+	path = filepath.Clean(path)
+	for _, p := range LibraryPaths() {
+		if strings.HasPrefix(path, filepath.Clean(p)+string(filepath.Separator)) {
+			return filepath.Join(p, ".trash")
+		}
+	}
+	return filepath.Join(config.Get().LibraryPath, ".trash")
+}
+
+func loadTrash() map[string]*trashEntry {
+	ret := map[string]*trashEntry{}
+	cache.NewDBStore().Get(cache.LibraryTrashKey, &ret)
+	return ret
+}
+
+func saveTrash(entries map[string]*trashEntry) {
+	cache.NewDBStore().Set(cache.LibraryTrashKey, entries, cache.LibraryTrashExpire)
+}
+
+// removeTitleDir removes title folder, or moves it to trash, if trash is enabled
+func removeTitleDir(path string, mediaType, tmdbID int) error {
+	if !config.Get().LibraryTrashEnabled {
+		return safeRemoveAll(path)
+	}
+
+	return moveToTrash(path, mediaType, tmdbID)
+}
+
+func moveToTrash(path string, mediaType, tmdbID int) error {
+	if isLibraryRoot(path) {
+		return ErrUnsafeRemoval
+	}
+
+	trash, err := ensureTrash(path)
+	if err != nil {
+		return err
+	}
+
+	trashMu.Lock()
+	defer trashMu.Unlock()
+
+	now := time.Now()
+	trashPath := filepath.Join(trash, fmt.Sprintf("%s.%d", filepath.Base(path), now.Unix()))
+	if err := libFS.Rename(path, trashPath); err != nil {
+		return err
+	}
+	// Untracked folders are purged by modification time, so it should be the time of trashing
+	libFS.Chtimes(trashPath, now, now)
+
+	entries := loadTrash()
+	entries[trashPath] = &trashEntry{
+		MediaType:    mediaType,
+		TMDBID:       tmdbID,
+		OriginalPath: path,
+		TrashedAt:    now,
+	}
+	saveTrash(entries)
+
+	log.Infof("Directory %s moved to trash", path)
+	return nil
+}
+
+// moveFlatToTrash moves movie files of flat layout into a trash folder of their own
+func moveFlatToTrash(strmPath string, files []string, tmdbID int) error {
+	trash, err := ensureTrash(strmPath)
+	if err != nil {
+		return err
+	}
+
+	trashMu.Lock()
+	defer trashMu.Unlock()
+
+	now := time.Now()
+	trashPath := filepath.Join(trash, fmt.Sprintf("%s.%d", strings.TrimSuffix(filepath.Base(strmPath), ".strm"), now.Unix()))
+	if err := libFS.Mkdir(trashPath, 0755); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := libFS.Rename(f, filepath.Join(trashPath, filepath.Base(f))); err != nil {
+			return err
+		}
+	}
+
+	entries := loadTrash()
+	entries[trashPath] = &trashEntry{
+		MediaType:    MovieType,
+		TMDBID:       tmdbID,
+		OriginalPath: strmPath,
+		TrashedAt:    now,
+		Flat:         true,
+	}
+	saveTrash(entries)
+
+	log.Infof("Movie files of %s moved to trash", strmPath)
+	return nil
+}
+
+// ensureTrash returns trash folder for the path, creating it if it is missing
+func ensureTrash(path string) (string, error) {
+	trash := trashPathOf(path)
+	if _, err := libFS.Stat(trash); os.IsNotExist(err) {
+		if err := libFS.Mkdir(trash, 0755); err != nil {
+			return trash, err
+		}
+	}
+	return trash, nil
+}
+
+// restoreFlatFiles moves trashed movie files of flat layout back next to each other
+func restoreFlatFiles(trashPath, strmPath string) error {
+	files, err := libFS.ReadDir(trashPath)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(strmPath)
+	for _, f := range files {
+		if err := libFS.Rename(filepath.Join(trashPath, f.Name()), filepath.Join(dir, f.Name())); err != nil {
+			return err
+		}
+	}
+	return libFS.RemoveAll(trashPath)
+}
+
+// RestoreFromTrash moves latest trashed folders of the title back to the library
+// and marks the title as active again
+func RestoreFromTrash(tmdbID int, mediaType int) error {
+	trashMu.Lock()
+	defer trashMu.Unlock()
+
+	entries := loadTrash()
+
+	// Only the most recent copy of each original folder is restored
+	latest := map[string]string{}
+	for trashPath, e := range entries {
+		if e.TMDBID != tmdbID || e.MediaType != mediaType {
+			continue
+		}
+		if p, ok := latest[e.OriginalPath]; !ok || entries[p].TrashedAt.Before(e.TrashedAt) {
+			latest[e.OriginalPath] = trashPath
+		}
+	}
+	if len(latest) == 0 {
+		return ErrNotInTrash
+	}
+
+	for originalPath, trashPath := range latest {
+		if _, err := libFS.Stat(originalPath); err == nil {
+			return fmt.Errorf("Folder %s already exists", originalPath)
+		}
+		if entries[trashPath].Flat {
+			if err := restoreFlatFiles(trashPath, originalPath); err != nil {
+				return err
+			}
+		} else if err := libFS.Rename(trashPath, originalPath); err != nil {
+			return err
+		}
+		delete(entries, trashPath)
+
+		roots := MoviesLibraryPaths()
+		if mediaType == ShowType {
+			roots = ShowsLibraryPaths()
+		}
+		root := mediaRootOf(originalPath, roots)
+		if root == "" {
+			root = roots[0]
+		}
+		updateManifest(root, &ManifestItem{
+			TMDBID:    tmdbID,
+			MediaType: mediaType,
+			Title:     strings.TrimSuffix(filepath.Base(originalPath), ".strm"),
+			Path:      originalPath,
+			WrittenAt: time.Now(),
+		})
+
+		log.Infof("Directory %s restored from trash", originalPath)
+		RequestScan(originalPath)
+	}
+	saveTrash(entries)
+
+	return deleteDBItem(tmdbID, mediaType, false)
+}
+
+// purgeTrash removes trashed folders, kept longer than configured number of days
+func purgeTrash() {
+	trashMu.Lock()
+	defer trashMu.Unlock()
+
+	entries := loadTrash()
+	keep := time.Duration(config.Get().LibraryTrashDays) * 24 * time.Hour
+	changed := false
+	for trashPath, e := range entries {
+		if time.Since(e.TrashedAt) < keep {
+			continue
+		}
+
+		if err := libFS.RemoveAll(trashPath); err != nil && !os.IsNotExist(err) {
+			log.Warningf("Could not purge %s from trash: %s", trashPath, err)
+			continue
+		}
+
+		log.Infof("Directory %s purged from trash", trashPath)
+		delete(entries, trashPath)
+		changed = true
+	}
+
+	if changed {
+		saveTrash(entries)
+	}
+
+	// Folders, which entries are lost, are purged by their modification time
+	for _, trash := range TrashLibraryPaths() {
+		children, err := libFS.ReadDir(trash)
+		if err != nil {
+			continue
+		}
+
+		for _, c := range children {
+			trashPath := filepath.Join(trash, c.Name())
+			if _, ok := entries[trashPath]; ok || time.Since(c.ModTime()) < keep {
+				continue
+			}
+
+			if err := libFS.RemoveAll(trashPath); err != nil {
+				log.Warningf("Could not purge %s from trash: %s", trashPath, err)
+				continue
+			}
+			log.Infof("Untracked %s purged from trash", trashPath)
+		}
+	}
+}
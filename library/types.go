@@ -1,5 +1,11 @@
 package library
 
+import (
+	"time"
+
+	"github.com/elgatito/elementum/database"
+)
+
 // DBItem ...
 type DBItem struct {
 	ID       int `json:"id"`
@@ -15,3 +21,146 @@ type removedEpisode struct {
 	Season   int
 	Episode  int
 }
+
+// NewEpisode identifies episode, written to the library for the first time
+type NewEpisode struct {
+	TMDBID   int    `json:"tmdb_id"`
+	ShowID   int    `json:"show_id"`
+	ShowName string `json:"show_name"`
+	Season   int    `json:"season"`
+	Episode  int    `json:"episode"`
+	Title    string `json:"title"`
+}
+
+// TitleUsage describes disk space taken by a single library title folder
+type TitleUsage struct {
+	TMDBID    int    `json:"tmdb_id"`
+	Title     string `json:"title"`
+	Path      string `json:"path"`
+	Bytes     int64  `json:"bytes"`
+	FileCount int    `json:"file_count"`
+}
+
+// Manifest lists active titles of a library root, for external tools
+type Manifest struct {
+	UpdatedAt time.Time       `json:"updated_at"`
+	Items     []*ManifestItem `json:"items"`
+}
+
+// ManifestItem ...
+type ManifestItem struct {
+	TMDBID    int       `json:"tmdb_id"`
+	MediaType int       `json:"media_type"`
+	Title     string    `json:"title"`
+	Path      string    `json:"path"`
+	WrittenAt time.Time `json:"written_at"`
+}
+
+// AddMovieResult describes outcome of adding a movie to the library
+type AddMovieResult struct {
+	Paths        []string `json:"paths"`
+	WasDuplicate bool     `json:"was_duplicate"`
+}
+
+// AddShowResult describes outcome of adding a show to the library
+type AddShowResult struct {
+	Paths           []string `json:"paths"`
+	EpisodesWritten int      `json:"episodes_written"`
+	WasDuplicate    bool     `json:"was_duplicate"`
+}
+
+// ReconcileReport describes differences between library database and strm files on disk
+type ReconcileReport struct {
+	OrphanFolders []string `json:"orphan_folders"`
+	GhostMovies   []int    `json:"ghost_movies"`
+	GhostShows    []int    `json:"ghost_shows"`
+	Adopted       int      `json:"adopted"`
+	Recreated     int      `json:"recreated"`
+	RemovedRows   int      `json:"removed_rows"`
+}
+
+// ScrubReport describes integrity issues, found by the library scrubber
+type ScrubReport struct {
+	EmptyDirs     []string `json:"empty_dirs"`
+	CorruptStrm   []string `json:"corrupt_strm"`
+	MissingMovies []int    `json:"missing_movies"`
+	MissingShows  []int    `json:"missing_shows"`
+	StaleRemoved  []string `json:"stale_removed"`
+	Repaired      int      `json:"repaired"`
+}
+
+// ShowRange describes episodes, written for a library show
+type ShowRange struct {
+	TMDBID   int           `json:"tmdb_id"`
+	Title    string        `json:"title"`
+	Path     string        `json:"path"`
+	Episodes int           `json:"episodes"`
+	Seasons  []SeasonRange `json:"seasons"`
+}
+
+// SeasonRange describes episodes, written for a single season
+type SeasonRange struct {
+	Season   int `json:"season"`
+	First    int `json:"first"`
+	Last     int `json:"last"`
+	Episodes int `json:"episodes"`
+}
+
+// SkippedImport describes list item, that was not added to the library by content filters
+type SkippedImport struct {
+	MediaType int       `json:"media_type"`
+	TMDBID    int       `json:"tmdb_id"`
+	Title     string    `json:"title"`
+	Reason    string    `json:"reason"`
+	SkippedAt time.Time `json:"skipped_at"`
+}
+
+// CleanupPlan lists paths, that cleanup passes would remove
+type CleanupPlan struct {
+	EmptyDirs     []string `json:"empty_dirs"`
+	Orphaned      []string `json:"orphaned"`
+	PrunedSeasons []string `json:"pruned_seasons"`
+}
+
+// LibraryStats summarizes library contents
+type LibraryStats struct {
+	ActiveMovies    int   `json:"active_movies"`
+	ActiveShows     int   `json:"active_shows"`
+	ActiveEpisodes  int   `json:"active_episodes"`
+	DeletedItems    int   `json:"deleted_items"`
+	PendingRemovals int   `json:"pending_removals"`
+	MoviesBytes     int64 `json:"movies_bytes"`
+	ShowsBytes      int64 `json:"shows_bytes"`
+}
+
+// SyncPreviewItem describes title, that list sync would write
+type SyncPreviewItem struct {
+	TMDBID int    `json:"tmdb_id"`
+	Title  string `json:"title"`
+}
+
+// LibraryExport is a backup of library items
+type LibraryExport struct {
+	SchemaVersion int                    `json:"schema_version"`
+	ExportedAt    time.Time              `json:"exported_at"`
+	Items         []database.LibraryItem `json:"items"`
+}
+
+// LibraryEvent describes a change of library contents, episodes have show and episode numbers set
+type LibraryEvent struct {
+	Action    string    `json:"action"`
+	MediaType int       `json:"media_type"`
+	TMDBID    int       `json:"tmdb_id"`
+	ShowID    int       `json:"show_id,omitempty"`
+	Season    int       `json:"season,omitempty"`
+	Episode   int       `json:"episode,omitempty"`
+	Paths     []string  `json:"paths"`
+	Time      time.Time `json:"time"`
+}
+
+// LibrarySearchResult describes library title, matching search query
+type LibrarySearchResult struct {
+	TMDBID    int    `json:"tmdb_id"`
+	MediaType int    `json:"media_type"`
+	Title     string `json:"title"`
+}
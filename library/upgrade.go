@@ -0,0 +1,267 @@
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/util"
+)
+
+// upgradeStep is a library repair, needed when upgrading from a version older than Version
+type upgradeStep struct {
+	Version string
+	Name    string
+	Run     func() error
+}
+
+// upgradeSteps should be kept sorted by version
+var upgradeSteps = []upgradeStep{
+	{Version: "0.1.60", Name: "database schema", Run: MigrateSchema},
+	{Version: "0.1.61", Name: "NFO backfill", Run: backfillNFO},
+	{Version: "0.1.62", Name: "episode filenames", Run: normalizeEpisodeFilenames},
+}
+
+// runUpgradeMigration runs post-upgrade migration from last migrated version to the current one
+func runUpgradeMigration() {
+	from := ""
+	cacheStore.Get(cache.LibraryMigratedVersionKey, &from)
+
+	if err := PostUpgradeMigration(from, util.GetVersion()); err != nil {
+		log.Errorf("Library migration after upgrade failed: %s", err)
+	}
+}
+
+// PostUpgradeMigration runs library repairs, introduced after fromVersion and up to toVersion.
+// Empty fromVersion means the library was never migrated, so all steps are executed.
+// Migrated version is stored on success, so each step is executed only once.
+func PostUpgradeMigration(fromVersion, toVersion string) error {
+	if cacheStore == nil {
+		InitDB()
+	}
+
+	migrated := ""
+	if err := cacheStore.Get(cache.LibraryMigratedVersionKey, &migrated); err == nil && migrated != "" && compareVersions(migrated, toVersion) >= 0 {
+		return nil
+	}
+
+	for _, step := range upgradeSteps {
+		if fromVersion != "" && compareVersions(step.Version, fromVersion) <= 0 {
+			continue
+		}
+		if compareVersions(step.Version, toVersion) > 0 {
+			break
+		}
+		if closer.IsSet() {
+			return ErrLibraryClosing
+		}
+
+		log.Infof("Running library migration for %s: %s", step.Version, step.Name)
+		if err := step.Run(); err != nil {
+			return fmt.Errorf("%s: %s", step.Name, err)
+		}
+	}
+
+	return cacheStore.Set(cache.LibraryMigratedVersionKey, toVersion, cache.LibraryMigratedVersionExpire)
+}
+
+// compareVersions compares dotted numeric versions, suffixes like "-dirty" are ignored
+func compareVersions(a, b string) int {
+	pa := versionParts(a)
+	pb := versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		va, vb := 0, 0
+		if i < len(pa) {
+			va = pa[i]
+		}
+		if i < len(pb) {
+			vb = pb[i]
+		}
+
+		if va < vb {
+			return -1
+		} else if va > vb {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+ "); i != -1 {
+		v = v[:i]
+	}
+
+	ret := []int{}
+	for _, p := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(p)
+		ret = append(ret, n)
+	}
+	return ret
+}
+
+// backfillNFO writes NFO files for library items, added before NFO writing was enabled
+func backfillNFO() error {
+	if config.Get().LibraryNFOMovies {
+		lis, err := ListLibraryItems(MovieType, StateActive)
+		if err != nil {
+			return err
+		}
+
+		for _, li := range lis {
+			if closer.IsSet() {
+				return ErrLibraryClosing
+			}
+
+			movie := tmdb.GetMovie(li.ID, config.Get().StrmLanguage)
+			if movie == nil {
+				continue
+			}
+
+			for dir := range getMoviePaths(movie) {
+				if isFlatMovieFile(dir) {
+					nfoPath := strings.TrimSuffix(dir, ".strm") + ".nfo"
+					if _, err := libFS.Stat(nfoPath); err != nil {
+						writeMovieNFO(movie, nfoPath)
+					}
+					continue
+				}
+
+				entries, err := libFS.ReadDir(dir)
+				if err != nil {
+					continue
+				}
+
+				for _, e := range entries {
+					if e.IsDir() || filepath.Ext(e.Name()) != ".strm" {
+						continue
+					}
+
+					nfoPath := filepath.Join(dir, strings.TrimSuffix(e.Name(), ".strm")+".nfo")
+					if _, err := libFS.Stat(nfoPath); err == nil {
+						continue
+					}
+					writeMovieNFO(movie, nfoPath)
+				}
+			}
+		}
+	}
+
+	if config.Get().LibraryNFOShows {
+		lis, err := ListLibraryItems(ShowType, StateActive)
+		if err != nil {
+			return err
+		}
+
+		for _, li := range lis {
+			if closer.IsSet() {
+				return ErrLibraryClosing
+			}
+
+			show := tmdb.GetShow(li.ID, config.Get().StrmLanguage)
+			if show == nil {
+				continue
+			}
+
+			for dir := range getShowPaths(show) {
+				nfoPath := filepath.Join(dir, "tvshow.nfo")
+				if _, err := libFS.Stat(nfoPath); err == nil {
+					continue
+				}
+				writeShowNFO(show, nfoPath, 0)
+			}
+		}
+	}
+
+	return nil
+}
+
+// normalizeEpisodeFilenames renames episode strm files to the current naming scheme
+// and removes duplicates, left by older versions
+func normalizeEpisodeFilenames() error {
+	lis, err := ListLibraryItems(ShowType, StateActive)
+	if err != nil {
+		return err
+	}
+
+	for _, li := range lis {
+		if closer.IsSet() {
+			return ErrLibraryClosing
+		}
+
+		show := tmdb.GetShow(li.ID, config.Get().StrmLanguage)
+		if show == nil {
+			continue
+		}
+
+		showPath, showStrm := getShowPath(show)
+		normalizeShowEpisodeFilenames(show, showPath, showStrm)
+	}
+
+	return nil
+}
+
+// normalizeShowEpisodeFilenames renames episode strm files of the show to the current naming scheme,
+// duplicates of already renamed episodes are removed
+func normalizeShowEpisodeFilenames(show *tmdb.Show, showPath, showStrm string) {
+	seasons := map[int]*tmdb.Season{}
+	for code, files := range episodeStrmFiles(showPath, showStrm) {
+		// Only codes, written exactly as SxxExx of the parsed numbers, are renamed,
+		// so S01E100 is never taken for S01E10
+		var season, episode int
+		if n, _ := fmt.Sscanf(code, "S%dE%d", &season, &episode); n != 2 || episodeCode(season, episode) != code {
+			continue
+		}
+
+		title := ""
+		if config.Get().IncludeEpisodeTitleInFilename {
+			s, ok := seasons[season]
+			if !ok {
+				s = tmdb.GetSeason(show.ID, season, config.Get().Language, len(show.Seasons))
+				seasons[season] = s
+			}
+			if s == nil {
+				continue
+			}
+			for _, e := range s.Episodes {
+				if e != nil && e.EpisodeNumber == episode {
+					title = e.Name
+					break
+				}
+			}
+		}
+
+		expected := filepath.Join(episodeDir(showPath, season), episodeStrmName(showStrm, season, episode, title))
+		hasExpected := false
+		for _, p := range files {
+			if p == expected {
+				hasExpected = true
+			}
+		}
+
+		for _, p := range files {
+			if p == expected {
+				continue
+			}
+			if !hasExpected {
+				if _, err := ensureEpisodeDir(showPath, season); err != nil {
+					log.Warningf("Could not create season folder for %s: %s", p, err)
+					continue
+				}
+				if err := renameEpisodeFile(p, expected); err != nil {
+					log.Warningf("Could not rename %s: %s", p, err)
+					continue
+				}
+				hasExpected = true
+				continue
+			}
+			removeEpisodeFile(p)
+		}
+	}
+}
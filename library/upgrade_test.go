@@ -0,0 +1,32 @@
+package library
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+func TestNormalizeShowEpisodeFilenamesLongCodes(t *testing.T) {
+	m := setupMemLibrary(t)
+	config.Get().ShowSeasonFolders = true
+	config.Get().IncludeEpisodeTitleInFilename = false
+
+	showPath := filepath.Join(ShowsLibraryPaths()[0], "Show (2020)")
+	e10 := filepath.Join(showPath, "Show (2020) S01E10.strm")
+	e100 := filepath.Join(showPath, "Show (2020) S01E100.strm")
+	writeMemFiles(t, m, e10, e100)
+
+	normalizeShowEpisodeFilenames(&tmdb.Show{Entity: tmdb.Entity{ID: 1}}, showPath, "Show (2020)")
+
+	assertMemFiles(t, m, false, e10, e100)
+	for moved, original := range map[string]string{
+		filepath.Join(showPath, "Season 01", "Show (2020) S01E10.strm"):  e10,
+		filepath.Join(showPath, "Season 01", "Show (2020) S01E100.strm"): e100,
+	} {
+		if data, err := m.ReadFile(moved); err != nil || string(data) != original {
+			t.Errorf("%s = %q, %v, want content of %s", moved, data, err, original)
+		}
+	}
+}
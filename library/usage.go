@@ -0,0 +1,106 @@
+package library
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+)
+
+// DiskUsageByTitle walks folders of each active library movie or show
+// and returns their sizes, sorted from the biggest to the smallest
+func DiskUsageByTitle(mediaType int) ([]TitleUsage, error) {
+	if mediaType != MovieType && mediaType != ShowType {
+		return nil, errors.New("Unsupported media type")
+	}
+
+	lis, err := ListLibraryItems(mediaType, StateActive)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := []TitleUsage{}
+	for _, li := range lis {
+		if li.ID == 0 {
+			continue
+		}
+
+		var title string
+		var paths map[string]bool
+		if mediaType == MovieType {
+			movie := tmdb.GetMovie(li.ID, config.Get().StrmLanguage)
+			if movie == nil {
+				continue
+			}
+			title = movie.Title
+			paths = getMoviePaths(movie)
+		} else {
+			show := tmdb.GetShow(li.ID, config.Get().StrmLanguage)
+			if show == nil {
+				continue
+			}
+			title = show.Name
+			paths = getShowPaths(show)
+		}
+
+		for path := range paths {
+			var bytes int64
+			var count int
+			if mediaType == MovieType && isFlatMovieFile(path) {
+				bytes, count = filesUsage(flatMovieFiles(path))
+			} else {
+				bytes, count = dirUsage(path)
+			}
+			ret = append(ret, TitleUsage{
+				TMDBID:    li.ID,
+				Title:     title,
+				Path:      path,
+				Bytes:     bytes,
+				FileCount: count,
+			})
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Bytes == ret[j].Bytes {
+			return ret[i].Path < ret[j].Path
+		}
+		return ret[i].Bytes > ret[j].Bytes
+	})
+
+	return ret, nil
+}
+
+func filesUsage(files []string) (size int64, count int) {
+	for _, f := range files {
+		if st, err := libFS.Stat(f); err == nil {
+			size += st.Size()
+			count++
+		}
+	}
+
+	return
+}
+
+func dirUsage(dir string) (size int64, count int) {
+	entries, err := libFS.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			s, c := dirUsage(filepath.Join(dir, e.Name()))
+			size += s
+			count += c
+			continue
+		}
+
+		size += e.Size()
+		count++
+	}
+
+	return
+}
@@ -0,0 +1,98 @@
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+)
+
+const (
+	// ActionAdded reports title, added to the library
+	ActionAdded = "added"
+	// ActionRemoved reports title or episode, removed from the library
+	ActionRemoved = "removed"
+	// ActionUpdated reports title, rewritten in the library
+	ActionUpdated = "updated"
+)
+
+const (
+	webhookQueueSize = 100
+	webhookTimeout   = 10 * time.Second
+)
+
+var (
+	webhookOnce  sync.Once
+	webhookQueue chan LibraryEvent
+)
+
+// libraryChanged reports change of library contents to subscribers and configured webhook
+func libraryChanged(e LibraryEvent) {
+	if e.Paths == nil {
+		e.Paths = []string{}
+	}
+	e.Time = time.Now()
+
+	publishEvent(e)
+	fireWebhook(e)
+}
+
+// fireWebhook queues event for delivery to webhook URL, so slow endpoint
+// does not block library operations, events are dropped when queue is full
+func fireWebhook(e LibraryEvent) {
+	if config.Get().LibraryWebhookURL == "" {
+		return
+	}
+
+	webhookOnce.Do(func() {
+		webhookQueue = make(chan LibraryEvent, webhookQueueSize)
+		go webhookWorker()
+	})
+
+	select {
+	case webhookQueue <- e:
+	default:
+		log.Warningf("Webhook queue is full, dropping %s event for %d", e.Action, e.TMDBID)
+	}
+}
+
+func webhookWorker() {
+	client := &http.Client{Timeout: webhookTimeout}
+
+	for {
+		select {
+		case <-closer.C():
+			return
+		case e := <-webhookQueue:
+			if err := postWebhook(client, config.Get().LibraryWebhookURL, e); err != nil {
+				log.Warningf("Dropping %s event for %d, webhook failed: %s", e.Action, e.TMDBID, err)
+			}
+		}
+	}
+}
+
+func postWebhook(client *http.Client, url string, e LibraryEvent) error {
+	if url == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return nil
+}
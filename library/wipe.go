@@ -0,0 +1,157 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/asdine/storm"
+
+	"github.com/elgatito/elementum/cache"
+	"github.com/elgatito/elementum/database"
+)
+
+// WipeLibrary removes all library items from the database in a single transaction,
+// with removeFiles set, folders and files of Elementum titles are removed from Movies and Shows folders,
+// anything, that is not known to be written by Elementum, is kept, as well as library roots themselves.
+// Callers are expected to ask for confirmation.
+func WipeLibrary(removeFiles bool) error {
+	if removeFiles {
+		if err := checkLibraryPath(); err != nil {
+			return err
+		}
+	}
+
+	tx, err := database.GetStormDB().Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.Select().Delete(&database.LibraryItem{}); err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	log.Notice("Library items are removed from the database")
+
+	cacheStore.Delete(cache.LibraryShowsLastUpdatesKey)
+	cacheStore.Delete(cache.LibraryMoviesLastUpdatesKey)
+	if cacheDB := database.GetCache(); cacheDB != nil {
+		cacheDB.DeleteWithPrefix(database.CommonBucket, []byte(cache.LibrarySyncCursorPrefix))
+	}
+	ClearResolveCache()
+
+	// Trashed folders are kept, without entries they are purged by age
+	trashMu.Lock()
+	cacheStore.Delete(cache.LibraryTrashKey)
+	trashMu.Unlock()
+
+	if !removeFiles {
+		return nil
+	}
+
+	var lastErr error
+	for _, root := range append(MoviesLibraryPaths(), ShowsLibraryPaths()...) {
+		entries, err := libFS.ReadDir(root)
+		if err != nil {
+			log.Warningf("Could not read library folder %s: %s", root, err)
+			lastErr = err
+			continue
+		}
+
+		manifested := manifestItemsByPath([]string{root})
+		strmNames := elementumStrmNames(root, entries)
+		for _, e := range entries {
+			p := filepath.Join(root, e.Name())
+			if e.IsDir() {
+				if _, ok := manifested[p]; !ok && !isElementumFolder(p) {
+					log.Infof("Keeping %s, it is not written by Elementum", p)
+					continue
+				}
+			} else if !isElementumFile(e.Name(), strmNames) {
+				continue
+			}
+
+			if err := libFS.RemoveAll(p); err != nil {
+				log.Warningf("Could not remove %s: %s", p, err)
+				lastErr = err
+			}
+		}
+		removeManifest(root)
+		log.Noticef("Elementum titles are removed from library folder %s", root)
+	}
+
+	return lastErr
+}
+
+// isElementumFolder checks if strm files of the folder point to an Elementum title
+func isElementumFolder(dir string) bool {
+	link := firstPlayLink(dir)
+	if link == nil {
+		return false
+	}
+
+	mediaType := link.MediaType
+	if mediaType == EpisodeType {
+		mediaType = ShowType
+	}
+	return folderBelongsTo(dir, mediaType, link.TMDBID)
+}
+
+// firstPlayLink returns play link of the first Elementum strm file in the folder or in its season folders
+func firstPlayLink(dir string) *PlayLink {
+	entries, err := libFS.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		if e.IsDir() {
+			if isSeasonFolder(e.Name()) {
+				if link := firstPlayLink(p); link != nil {
+					return link
+				}
+			}
+			continue
+		} else if !strings.HasSuffix(e.Name(), ".strm") {
+			continue
+		}
+
+		if content, err := libFS.ReadFile(p); err == nil {
+			if link, err := ResolvePlayLink(string(content)); err == nil {
+				return link
+			}
+		}
+	}
+	return nil
+}
+
+// elementumStrmNames returns names without extension of strm files in root, pointing to Elementum titles,
+// so files of flat movies are recognized by the name
+func elementumStrmNames(root string, entries []os.FileInfo) []string {
+	ret := []string{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".strm") {
+			continue
+		}
+		if content, err := libFS.ReadFile(filepath.Join(root, e.Name())); err == nil {
+			if _, err := ResolvePlayLink(string(content)); err == nil {
+				ret = append(ret, strings.TrimSuffix(e.Name(), ".strm"))
+			}
+		}
+	}
+	return ret
+}
+
+// isElementumFile checks if file is an Elementum strm file in root or its nfo or artwork
+func isElementumFile(name string, strmNames []string) bool {
+	for _, strm := range strmNames {
+		if rest := strings.TrimPrefix(name, strm); rest != name && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "-")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package library
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestAcquireShowWriteSerializes runs concurrent writes of the same shows, meant to be run with -race:
+// writes of one show should never overlap, while different shows are written in parallel
+func TestAcquireShowWriteSerializes(t *testing.T) {
+	m := setupMemLibrary(t)
+	root := ShowsLibraryPaths()[0]
+
+	const shows, writers, episodes = 3, 8, 5
+
+	// written is changed without atomics, so overlapping writes of a show are reported by race detector
+	written := make([]int, shows)
+	inside := make([]int32, shows)
+	var wg sync.WaitGroup
+
+	for w := 0; w < writers; w++ {
+		for s := 0; s < shows; s++ {
+			wg.Add(1)
+			go func(s int) {
+				defer wg.Done()
+				defer acquireShowWrite(s + 1)()
+
+				if n := atomic.AddInt32(&inside[s], 1); n != 1 {
+					t.Errorf("show %d is written by %d goroutines at once", s+1, n)
+				}
+				defer atomic.AddInt32(&inside[s], -1)
+
+				showPath := filepath.Join(root, fmt.Sprintf("Show %d", s+1))
+				if _, err := m.Stat(showPath); err != nil {
+					if err := m.Mkdir(showPath, 0755); err != nil {
+						t.Errorf("Mkdir(%s) = %v, folder is created twice", showPath, err)
+						return
+					}
+				}
+				for e := 1; e <= episodes; e++ {
+					p := filepath.Join(showPath, episodeStrmName(filepath.Base(showPath), 1, e, ""))
+					if isUnchangedFile(p, []byte(p)) {
+						continue
+					}
+					if err := m.WriteFile(p, []byte(p), 0644); err != nil {
+						t.Error(err)
+					}
+				}
+				written[s]++
+			}(s)
+		}
+	}
+	wg.Wait()
+
+	for s := 0; s < shows; s++ {
+		if written[s] != writers {
+			t.Errorf("show %d is written %d times, want %d", s+1, written[s], writers)
+		}
+		files := episodeStrmFiles(filepath.Join(root, fmt.Sprintf("Show %d", s+1)), fmt.Sprintf("Show %d", s+1))
+		if len(files) != episodes {
+			t.Errorf("show %d has %d episodes, want %d", s+1, len(files), episodes)
+		}
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(writingShows) != 0 {
+		t.Errorf("writingShows keeps %d shows after all writes finished", len(writingShows))
+	}
+}
@@ -0,0 +1,148 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elgatito/elementum/config"
+	"github.com/elgatito/elementum/tmdb"
+	"github.com/elgatito/elementum/xbmc"
+)
+
+// MigrateShowFolder moves existing show folder to the path, derived from current TMDB details,
+// episode strm files are renamed to match new folder name, other contents are kept as is.
+func MigrateShowFolder(show *tmdb.Show, oldPath string) (string, error) {
+	// Show folder stays in its library path, moving it to another drive is not a rename
+	root := mediaRootOf(oldPath, ShowsLibraryPaths())
+	if root == "" {
+		root = newTitleRoot(ShowsLibraryPaths())
+	}
+	newPath, newStrm := expectedShowPath(show, root)
+	if newPath == filepath.Clean(oldPath) {
+		return newPath, nil
+	}
+	if _, err := libFS.Stat(newPath); err == nil {
+		return "", fmt.Errorf("Folder %s already exists", newPath)
+	}
+
+	preserveShowState(show.ID)
+
+	if err := libFS.Rename(oldPath, newPath); err != nil {
+		return "", err
+	}
+
+	renameEpisodePrefixes(newPath, filepath.Base(oldPath)+" ", newStrm+" ", true)
+
+	updateManifest(filepath.Dir(newPath), &ManifestItem{
+		TMDBID:    show.ID,
+		MediaType: ShowType,
+		Title:     show.Name,
+		Path:      newPath,
+		WrittenAt: time.Now(),
+	})
+
+	log.Infof("Moved show folder %s to %s", oldPath, newPath)
+	return newPath, nil
+}
+
+// renameEpisodePrefixes renames episode files of the show folder and its season folders,
+// which names start with old show folder name, to start with the new one
+func renameEpisodePrefixes(dir, oldPrefix, newPrefix string, seasons bool) {
+	entries, err := libFS.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() && seasons && isSeasonFolder(name) {
+			renameEpisodePrefixes(filepath.Join(dir, name), oldPrefix, newPrefix, false)
+			continue
+		} else if e.IsDir() || !strings.HasPrefix(name, oldPrefix) {
+			continue
+		}
+
+		renamed := newPrefix + strings.TrimPrefix(name, oldPrefix)
+		if err := libFS.Rename(filepath.Join(dir, name), filepath.Join(dir, renamed)); err != nil {
+			log.Warningf("Could not rename %s: %s", name, err)
+		}
+	}
+}
+
+// FixShowYears renames folders of active shows, which year does not match
+// corrected TMDB premiere date anymore, returns number of fixed shows
+func FixShowYears() (int, error) {
+	lis, err := ListLibraryItems(ShowType, StateActive)
+	if err != nil {
+		return 0, err
+	}
+
+	manifestPaths := map[int]string{}
+	for _, root := range ShowsLibraryPaths() {
+		if m, err := ReadManifest(root); err == nil {
+			for _, mi := range m.Items {
+				if mi != nil && mi.MediaType == ShowType {
+					manifestPaths[mi.TMDBID] = mi.Path
+				}
+			}
+		}
+	}
+
+	fixed := 0
+	for _, li := range lis {
+		if closer.IsSet() {
+			return fixed, ErrLibraryClosing
+		}
+
+		show := tmdb.GetShow(li.ID, config.Get().StrmLanguage)
+		if show == nil {
+			continue
+		}
+
+		paths := getShowPathsByTMDB(show.ID)
+		if p, ok := manifestPaths[show.ID]; ok && p != "" {
+			paths[p] = true
+		}
+
+		for oldPath := range paths {
+			root := mediaRootOf(oldPath, ShowsLibraryPaths())
+			if root == "" || root != filepath.Dir(filepath.Clean(oldPath)) {
+				continue
+			}
+			if _, err := libFS.Stat(oldPath); os.IsNotExist(err) {
+				continue
+			}
+
+			// Folder, known to Kodi, is the old one, so expected folder is derived from TMDB details
+			expected, _ := expectedShowPath(show, root)
+			if expected == filepath.Clean(oldPath) {
+				break
+			}
+			if _, err := libFS.Stat(expected); err == nil {
+				break
+			}
+
+			newPath, err := MigrateShowFolder(show, oldPath)
+			if err != nil {
+				log.Warningf("Could not fix year of %s: %s", show.Name, err)
+				continue
+			}
+
+			xbmc.VideoLibraryCleanDirectory(oldPath, "tvshows", false)
+			RequestScan(newPath)
+			fixed++
+			break
+		}
+	}
+
+	return fixed, nil
+}
+
+// expectedShowPath returns folder and its name, the show should have in root according to current TMDB details
+func expectedShowPath(show *tmdb.Show, root string) (showPath, showStrm string) {
+	showStrm = fitNameLogged(showFolderName(show), showNameRoom(root))
+	return filepath.Join(root, showStrm), showStrm
+}
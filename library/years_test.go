@@ -0,0 +1,80 @@
+package library
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/elgatito/elementum/tmdb"
+)
+
+func TestMigrateShowFolderRenamedShow(t *testing.T) {
+	m := setupMemLibrary(t)
+	root := ShowsLibraryPaths()[0]
+
+	oldPath := filepath.Join(root, "Old Name (2019)")
+	writeMemFiles(t, m,
+		filepath.Join(oldPath, "Old Name (2019) S01E01.strm"),
+		filepath.Join(oldPath, "Old Name (2019) S01E02.strm"),
+		filepath.Join(oldPath, "tvshow.nfo"),
+	)
+
+	show := &tmdb.Show{Entity: tmdb.Entity{ID: 1, FirstAirDate: "2020-01-10", OriginalName: "New Name"}}
+
+	expected, _ := expectedShowPath(show, root)
+	if want := filepath.Join(root, "New Name (2020)"); expected != want {
+		t.Fatalf("expectedShowPath() = %s, want %s", expected, want)
+	}
+
+	newPath, err := MigrateShowFolder(show, oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newPath != expected {
+		t.Errorf("MigrateShowFolder() = %s, want %s", newPath, expected)
+	}
+
+	assertMemFiles(t, m, false, oldPath)
+	assertMemFiles(t, m, true,
+		filepath.Join(newPath, "New Name (2020) S01E01.strm"),
+		filepath.Join(newPath, "New Name (2020) S01E02.strm"),
+		filepath.Join(newPath, "tvshow.nfo"),
+	)
+
+	// Migrating again is a no-op, as the folder already matches TMDB details
+	if again, err := MigrateShowFolder(show, newPath); err != nil || again != newPath {
+		t.Errorf("MigrateShowFolder() = %s, %v, want %s, nil", again, err, newPath)
+	}
+}
+
+func TestMigrateShowFolderSeasonFolders(t *testing.T) {
+	m := setupMemLibrary(t)
+	root := ShowsLibraryPaths()[0]
+
+	oldPath := filepath.Join(root, "Show (2019)")
+	writeMemFiles(t, m,
+		filepath.Join(oldPath, "Season 01", "Show (2019) S01E01.strm"),
+		filepath.Join(oldPath, "Season 01", "Show (2019) S01E01.nfo"),
+		filepath.Join(oldPath, "Season 01", "season.nfo"),
+		filepath.Join(oldPath, "Season 02", "Show (2019) S02E01.strm"),
+		filepath.Join(oldPath, "Extras", "Show (2019) Extra.strm"),
+	)
+
+	show := &tmdb.Show{Entity: tmdb.Entity{ID: 1, FirstAirDate: "2020-01-10", OriginalName: "Show"}}
+	newPath, err := MigrateShowFolder(show, oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertMemFiles(t, m, true,
+		filepath.Join(newPath, "Season 01", "Show (2020) S01E01.strm"),
+		filepath.Join(newPath, "Season 01", "Show (2020) S01E01.nfo"),
+		filepath.Join(newPath, "Season 01", "season.nfo"),
+		filepath.Join(newPath, "Season 02", "Show (2020) S02E01.strm"),
+		// Only season folders hold episodes, other folders are left as they are
+		filepath.Join(newPath, "Extras", "Show (2019) Extra.strm"),
+	)
+	assertMemFiles(t, m, false,
+		filepath.Join(newPath, "Season 01", "Show (2019) S01E01.strm"),
+		filepath.Join(newPath, "Season 02", "Show (2019) S02E01.strm"),
+	)
+}
@@ -173,6 +173,7 @@ func main() {
 	}))
 	http.Handle("/reload", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		s.Reconfigure()
+		library.Reconfigure()
 		w.Write([]byte("true"))
 	}))
 	http.Handle("/notification", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
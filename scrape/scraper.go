@@ -98,7 +98,9 @@ func runUpdater() {
 
 		// Update Kodi library if needed
 		if libraryUpdated {
-			xbmc.VideoLibraryScanDirectory(library.MoviesLibraryPath(), true)
+			for _, root := range library.MoviesLibraryPaths() {
+				xbmc.VideoLibraryScanDirectory(root, true)
+			}
 		}
 	}()
 
@@ -199,7 +201,7 @@ func addMovieToLibrary(m *trakt.Movie) {
 		return
 	}
 
-	library.AddMovie(strconv.Itoa(m.IDs.TMDB), false)
+	library.AddMovie(strconv.Itoa(m.IDs.TMDB), false, "")
 	if config.Get().TraktToken != "" && config.Get().TraktSyncAddedMovies {
 		go trakt.SyncAddedItem("movies", strconv.Itoa(m.IDs.TMDB), config.Get().TraktSyncAddedMoviesLocation)
 	}
@@ -109,6 +109,29 @@ func GetMovies(tmdbIds []int, language string) Movies {
 	return movies
 }
 
+// GetCollection ...
+func GetCollection(collectionID int, language string) *Collection {
+	var collection *Collection
+	cacheStore := cache.NewDBStore()
+	key := fmt.Sprintf(cache.TMDBCollectionKey, collectionID, language)
+	if err := cacheStore.Get(key, &collection); err != nil {
+		err = MakeRequest(APIRequest{
+			URL: fmt.Sprintf("%s/collection/%d", tmdbEndpoint, collectionID),
+			Params: napping.Params{
+				"api_key":  apiKey,
+				"language": language,
+			}.AsUrlValues(),
+			Result:      &collection,
+			Description: "collection",
+		})
+
+		if collection != nil {
+			cacheStore.Set(key, collection, cache.TMDBCollectionExpire)
+		}
+	}
+	return collection
+}
+
 // GetMovieGenres ...
 func GetMovieGenres(language string) []*Genre {
 	genres := GenreList{}
@@ -287,6 +287,145 @@ func (z *Cast) Msgsize() (s int) {
 	return
 }
 
+// MarshalMsg implements msgp.Marshaler
+func (z *Collection) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 6
+	// string "ID"
+	o = append(o, 0x86, 0xa2, 0x49, 0x44)
+	o = msgp.AppendInt(o, z.ID)
+	// string "Name"
+	o = append(o, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	o = msgp.AppendString(o, z.Name)
+	// string "Overview"
+	o = append(o, 0xa8, 0x4f, 0x76, 0x65, 0x72, 0x76, 0x69, 0x65, 0x77)
+	o = msgp.AppendString(o, z.Overview)
+	// string "PosterPath"
+	o = append(o, 0xaa, 0x50, 0x6f, 0x73, 0x74, 0x65, 0x72, 0x50, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.PosterPath)
+	// string "BackdropPath"
+	o = append(o, 0xac, 0x42, 0x61, 0x63, 0x6b, 0x64, 0x72, 0x6f, 0x70, 0x50, 0x61, 0x74, 0x68)
+	o = msgp.AppendString(o, z.BackdropPath)
+	// string "Parts"
+	o = append(o, 0xa5, 0x50, 0x61, 0x72, 0x74, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Parts)))
+	for za0001 := range z.Parts {
+		if z.Parts[za0001] == nil {
+			o = msgp.AppendNil(o)
+		} else {
+			o, err = z.Parts[za0001].MarshalMsg(o)
+			if err != nil {
+				err = msgp.WrapError(err, "Parts", za0001)
+				return
+			}
+		}
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *Collection) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "ID":
+			z.ID, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ID")
+				return
+			}
+		case "Name":
+			z.Name, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "Overview":
+			z.Overview, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Overview")
+				return
+			}
+		case "PosterPath":
+			z.PosterPath, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "PosterPath")
+				return
+			}
+		case "BackdropPath":
+			z.BackdropPath, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "BackdropPath")
+				return
+			}
+		case "Parts":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Parts")
+				return
+			}
+			if cap(z.Parts) >= int(zb0002) {
+				z.Parts = (z.Parts)[:zb0002]
+			} else {
+				z.Parts = make([]*Entity, zb0002)
+			}
+			for za0001 := range z.Parts {
+				if msgp.IsNil(bts) {
+					bts, err = msgp.ReadNilBytes(bts)
+					if err != nil {
+						return
+					}
+					z.Parts[za0001] = nil
+				} else {
+					if z.Parts[za0001] == nil {
+						z.Parts[za0001] = new(Entity)
+					}
+					bts, err = z.Parts[za0001].UnmarshalMsg(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Parts", za0001)
+						return
+					}
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *Collection) Msgsize() (s int) {
+	s = 1 + 3 + msgp.IntSize + 5 + msgp.StringPrefixSize + len(z.Name) + 9 + msgp.StringPrefixSize + len(z.Overview) + 11 + msgp.StringPrefixSize + len(z.PosterPath) + 13 + msgp.StringPrefixSize + len(z.BackdropPath) + 6 + msgp.ArrayHeaderSize
+	for za0001 := range z.Parts {
+		if z.Parts[za0001] == nil {
+			s += msgp.NilSize
+		} else {
+			s += z.Parts[za0001].Msgsize()
+		}
+	}
+	return
+}
+
 // MarshalMsg implements msgp.Marshaler
 func (z ContentRating) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
@@ -3213,9 +3352,9 @@ func (z *List) Msgsize() (s int) {
 // MarshalMsg implements msgp.Marshaler
 func (z *Movie) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 18
+	// map header, size 19
 	// string "Entity"
-	o = append(o, 0xde, 0x0, 0x12, 0xa6, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79)
+	o = append(o, 0xde, 0x0, 0x13, 0xa6, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79)
 	o, err = z.Entity.MarshalMsg(o)
 	if err != nil {
 		err = msgp.WrapError(err, "Entity")
@@ -3425,6 +3564,17 @@ func (z *Movie) MarshalMsg(b []byte) (o []byte, err error) {
 			}
 		}
 	}
+	// string "BelongsToCollection"
+	o = append(o, 0xb3, 0x42, 0x65, 0x6c, 0x6f, 0x6e, 0x67, 0x73, 0x54, 0x6f, 0x43, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e)
+	if z.BelongsToCollection == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.BelongsToCollection.MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "BelongsToCollection")
+			return
+		}
+	}
 	return
 }
 
@@ -4007,6 +4157,23 @@ func (z *Movie) UnmarshalMsg(bts []byte) (o []byte, err error) {
 					}
 				}
 			}
+		case "BelongsToCollection":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.BelongsToCollection = nil
+			} else {
+				if z.BelongsToCollection == nil {
+					z.BelongsToCollection = new(Collection)
+				}
+				bts, err = z.BelongsToCollection.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "BelongsToCollection")
+					return
+				}
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -4121,6 +4288,12 @@ func (z *Movie) Msgsize() (s int) {
 			}
 		}
 	}
+	s += 20
+	if z.BelongsToCollection == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.BelongsToCollection.Msgsize()
+	}
 	return
 }
 
@@ -2,6 +2,7 @@ package tmdb
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"net/url"
@@ -72,6 +73,8 @@ type Movie struct {
 	Images  *Images  `json:"images,omitempty"`
 
 	ReleaseDates *ReleaseDatesResults `json:"release_dates"`
+
+	BelongsToCollection *Collection `json:"belongs_to_collection"`
 }
 
 // Show ...
@@ -339,6 +342,16 @@ type List struct {
 	Items         []*Entity `json:"items"`
 }
 
+// Collection ...
+type Collection struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Overview     string    `json:"overview"`
+	PosterPath   string    `json:"poster_path"`
+	BackdropPath string    `json:"backdrop_path"`
+	Parts        []*Entity `json:"parts"`
+}
+
 // Trailer ...
 type Trailer struct {
 	Name   string `json:"name"`
@@ -603,6 +616,22 @@ func GetLanguages(language string) []*Language {
 	return languages
 }
 
+// IsNotFound checks with uncached request, that TMDB answers "not found" for the title,
+// kind is "movie" or "tv". Network errors and rate limits are not treated as missing title.
+func IsNotFound(kind string, tmdbID int) bool {
+	var result *struct {
+		ID int `json:"id"`
+	}
+	err := MakeRequest(APIRequest{
+		URL:         fmt.Sprintf("%s/%s/%d", tmdbEndpoint, kind, tmdbID),
+		Params:      napping.Params{"api_key": apiKey}.AsUrlValues(),
+		Result:      &result,
+		Description: kind,
+	})
+
+	return err == util.ErrNotFound
+}
+
 // MakeRequest used to proxy requests with proper RateLimiter usage and HTTP error processing
 func MakeRequest(r APIRequest) (ret error) {
 	rl.Call(func() error {
@@ -649,6 +678,48 @@ func MakeRequest(r APIRequest) (ret error) {
 	return
 }
 
+// DownloadImage fetches image content from TMDB, using API rate limiter
+func DownloadImage(uri string, size string) (data []byte, ret error) {
+	if uri == "" {
+		return nil, util.ErrNotFound
+	}
+
+	rl.Call(func() error {
+		httpTransport := &http.Transport{}
+		if config.Get().ProxyURL != "" {
+			proxyUrl, _ := url.Parse(config.Get().ProxyURL)
+			httpTransport.Proxy = http.ProxyURL(proxyUrl)
+		}
+		httpClient := &http.Client{
+			Transport: httpTransport,
+			Timeout:   30 * time.Second,
+		}
+
+		resp, err := httpClient.Get(ImageURL(uri, size))
+		if err != nil {
+			log.Errorf("Failed to download image %s: %s", uri, err)
+			ret = err
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == 429 {
+			rl.CoolDown(resp.Header)
+			ret = util.ErrExceeded
+			return util.ErrExceeded
+		} else if resp.StatusCode != 200 {
+			log.Errorf("Bad status downloading image %s: %d", uri, resp.StatusCode)
+			ret = util.ErrHTTP
+			return util.ErrHTTP
+		}
+
+		data, ret = ioutil.ReadAll(resp.Body)
+		return ret
+	})
+
+	return
+}
+
 // GetCountries returns list of countries
 func (movie *Movie) GetCountries() []string {
 	countries := make([]string, 0, len(movie.ProductionCountries))
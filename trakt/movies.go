@@ -287,6 +287,8 @@ func WatchlistMovies(isUpdateNeeded bool) (movies []*Movies, err error) {
 
 	if err != nil {
 		return movies, err
+	} else if resp.Status() == 429 {
+		return movies, ErrRateLimited
 	} else if resp.Status() != 200 {
 		return movies, fmt.Errorf("Bad status getting Trakt watchlist for movies: %d", resp.Status())
 	}
@@ -333,6 +335,8 @@ func CollectionMovies(isUpdateNeeded bool) (movies []*Movies, err error) {
 
 	if errGet != nil {
 		return movies, errGet
+	} else if resp.Status() == 429 {
+		return movies, ErrRateLimited
 	} else if resp.Status() != 200 {
 		return movies, fmt.Errorf("Bad status getting Trakt collection for movies: %d", resp.Status())
 	}
@@ -490,6 +494,9 @@ func TopLists(page string) (lists []*ListContainer, hasNext bool) {
 
 // ListItemsMovies ...
 func ListItemsMovies(user string, listID string, isUpdateNeeded bool) (movies []*Movies, err error) {
+	if owner, list := ParseListID(listID); owner != "" {
+		user, listID = owner, list
+	}
 	if user == "" || user == "id" {
 		user = config.Get().TraktUsername
 	}
@@ -501,7 +508,7 @@ func ListItemsMovies(user string, listID string, isUpdateNeeded bool) (movies []
 	var resp *napping.Response
 
 	cacheStore := cache.NewDBStore()
-	key := fmt.Sprintf(cache.TraktMoviesListKey, listID)
+	key := fmt.Sprintf(cache.TraktMoviesListKey, listCacheID(user, listID))
 
 	if !isUpdateNeeded {
 		if err := cacheStore.Get(key, &movies); err == nil {
@@ -516,13 +523,18 @@ func ListItemsMovies(user string, listID string, isUpdateNeeded bool) (movies []
 		resp, errGet = GetWithAuth(endPoint, params)
 	}
 
-	if errGet != nil || resp.Status() != 200 {
+	if errGet == nil && resp.Status() == 429 {
+		return movies, ErrRateLimited
+	} else if errGet != nil {
 		return movies, errGet
+	} else if resp.Status() != 200 {
+		return movies, fmt.Errorf("Bad status getting Trakt list %s of %s for movies: %d", listID, user, resp.Status())
 	}
 
 	var list []*ListItem
 	if err = resp.Unmarshal(&list); err != nil {
 		log.Warning(err)
+		return movies, err
 	}
 
 	movieListing := make([]*Movies, 0)
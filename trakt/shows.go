@@ -466,6 +466,8 @@ func WatchlistShows(isUpdateNeeded bool) (shows []*Shows, err error) {
 
 	if err != nil {
 		return shows, err
+	} else if resp.Status() == 429 {
+		return shows, ErrRateLimited
 	} else if resp.Status() != 200 {
 		log.Error(err)
 		return shows, fmt.Errorf("Bad status getting Trakt watchlist for shows: %d", resp.Status())
@@ -522,6 +524,8 @@ func CollectionShows(isUpdateNeeded bool) (shows []*Shows, err error) {
 
 	if err != nil {
 		return shows, err
+	} else if resp.Status() == 429 {
+		return shows, ErrRateLimited
 	} else if resp.Status() != 200 {
 		return shows, fmt.Errorf("Bad status getting Trakt collection for shows: %d", resp.Status())
 	}
@@ -554,6 +558,9 @@ func PreviousCollectionShows() (shows []*Shows, err error) {
 
 // ListItemsShows ...
 func ListItemsShows(user string, listID string, isUpdateNeeded bool) (shows []*Shows, err error) {
+	if owner, list := ParseListID(listID); owner != "" {
+		user, listID = owner, list
+	}
 	if user == "" || user == "id" {
 		user = config.Get().TraktUsername
 	}
@@ -565,7 +572,7 @@ func ListItemsShows(user string, listID string, isUpdateNeeded bool) (shows []*S
 	var resp *napping.Response
 
 	cacheStore := cache.NewDBStore()
-	key := fmt.Sprintf(cache.TraktShowsListKey, listID)
+	key := fmt.Sprintf(cache.TraktShowsListKey, listCacheID(user, listID))
 
 	if !isUpdateNeeded {
 		if err := cacheStore.Get(key, &shows); err == nil {
@@ -580,13 +587,18 @@ func ListItemsShows(user string, listID string, isUpdateNeeded bool) (shows []*S
 		resp, errGet = GetWithAuth(endPoint, params)
 	}
 
-	if errGet != nil || resp.Status() != 200 {
+	if errGet == nil && resp.Status() == 429 {
+		return shows, ErrRateLimited
+	} else if errGet != nil {
 		return shows, errGet
+	} else if resp.Status() != 200 {
+		return shows, fmt.Errorf("Bad status getting Trakt list %s of %s for shows: %d", listID, user, resp.Status())
 	}
 
 	var list []*ListItem
 	if err = resp.Unmarshal(&list); err != nil {
 		log.Warning(err)
+		return shows, err
 	}
 
 	showListing := make([]*Shows, 0)
@@ -608,7 +620,7 @@ func ListItemsShows(user string, listID string, isUpdateNeeded bool) (shows []*S
 // PreviousListItemsShows ...
 func PreviousListItemsShows(listID string) (shows []*Shows, err error) {
 	cacheStore := cache.NewDBStore()
-	key := fmt.Sprintf(cache.TraktShowsListKey, listID)
+	key := fmt.Sprintf(cache.TraktShowsListKey, listCacheID(ParseListID(listID)))
 	err = cacheStore.Get(key, &shows)
 
 	return
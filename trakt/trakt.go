@@ -61,6 +61,8 @@ const (
 var (
 	// ErrLocked reflects Trakt account locked status
 	ErrLocked = errors.New("Account is locked")
+	// ErrRateLimited is returned when Trakt keeps answering with 429 after cooling down
+	ErrRateLimited = errors.New("Rate limit exceeded")
 )
 
 var rl = util.NewRateLimiter(burstRate, burstTime, simultaneousConnections)
@@ -1108,6 +1110,30 @@ func AddToWatchlist(itemType string, tmdbID string) (resp *napping.Response, err
 	return Post(endPoint, bytes.NewBufferString(fmt.Sprintf(`{"%s": [{"ids": {"tmdb": %s}}]}`, itemType, tmdbID)))
 }
 
+// UserListPrefix marks list ID of another Trakt user, formatted as user:username/listslug
+const UserListPrefix = "user:"
+
+// ParseListID splits list ID into list owner and list ID, plain list IDs have no owner
+func ParseListID(listID string) (user string, list string) {
+	if strings.HasPrefix(listID, UserListPrefix) {
+		parts := strings.SplitN(strings.TrimPrefix(listID, UserListPrefix), "/", 2)
+		if len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+			return parts[0], parts[1]
+		}
+	}
+
+	return "", listID
+}
+
+// listCacheID returns list ID for cache keys, so same list slugs of different users do not collide
+func listCacheID(user string, listID string) string {
+	if user == "" || user == "id" || user == config.Get().TraktUsername {
+		return listID
+	}
+
+	return UserListPrefix + user + "/" + listID
+}
+
 // AddToUserlist ...
 func AddToUserlist(listID int, itemType string, tmdbID string) (resp *napping.Response, err error) {
 	if err := Authorized(); err != nil {
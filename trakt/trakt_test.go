@@ -0,0 +1,61 @@
+package trakt
+
+import (
+	"testing"
+
+	"github.com/elgatito/elementum/config"
+)
+
+func TestParseListID(t *testing.T) {
+	tests := []struct {
+		listID string
+		user   string
+		list   string
+	}{
+		{"12345", "", "12345"},
+		{"my-list", "", "my-list"},
+		{"user:bob/favorites", "bob", "favorites"},
+		{"user:bob/123", "bob", "123"},
+		{"user:bob/nested/slug", "bob", "nested/slug"},
+		{"user:/favorites", "", "user:/favorites"},
+		{"user:bob/", "", "user:bob/"},
+		{"user:bob", "", "user:bob"},
+	}
+
+	for _, tt := range tests {
+		user, list := ParseListID(tt.listID)
+		if user != tt.user || list != tt.list {
+			t.Errorf("ParseListID(%q) = %q, %q, want %q, %q", tt.listID, user, list, tt.user, tt.list)
+		}
+	}
+}
+
+func TestListCacheID(t *testing.T) {
+	conf := config.Get()
+	saved := conf.TraktUsername
+	conf.TraktUsername = "me"
+	defer func() { conf.TraktUsername = saved }()
+
+	tests := []struct {
+		user   string
+		listID string
+		want   string
+	}{
+		{"", "12345", "12345"},
+		{"id", "12345", "12345"},
+		{"me", "favorites", "favorites"},
+		{"bob", "favorites", "user:bob/favorites"},
+		{"alice", "favorites", "user:alice/favorites"},
+	}
+
+	for _, tt := range tests {
+		if got := listCacheID(tt.user, tt.listID); got != tt.want {
+			t.Errorf("listCacheID(%q, %q) = %q, want %q", tt.user, tt.listID, got, tt.want)
+		}
+	}
+
+	// Full list IDs of different owners get different cache IDs
+	if a, b := listCacheID(ParseListID("user:bob/favorites")), listCacheID(ParseListID("user:alice/favorites")); a == b {
+		t.Errorf("lists of different users share cache ID %q", a)
+	}
+}
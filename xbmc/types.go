@@ -369,6 +369,12 @@ func (s *KodiTime) UnmarshalJSON(b []byte) (err error) {
 	return
 }
 
+// Profile ...
+type Profile struct {
+	Label    string `json:"label"`
+	LockMode int    `json:"lockmode"`
+}
+
 // NewView ...
 func NewView(contentType string, items ListItems) *View {
 	return &View{
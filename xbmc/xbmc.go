@@ -54,6 +54,12 @@ func VideoLibraryCleanDirectory(directory string, content string, showDialogs bo
 	return
 }
 
+// GetCurrentProfile returns currently active Kodi profile
+func GetCurrentProfile() (profile *Profile, err error) {
+	err = executeJSONRPCO("Profiles.GetCurrentProfile", &profile, nil)
+	return
+}
+
 // VideoLibraryGetMovies ...
 func VideoLibraryGetMovies() (movies *VideoLibraryMovies, err error) {
 	defer perf.ScopeTimer()()